@@ -0,0 +1,193 @@
+package geonames
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// defaultCacheCompletion is the largest unread remainder of a response that is
+// still downloaded on Close to complete the cached copy. Streaming an archive
+// stops after the wanted entry, which leaves the central directory unread.
+const defaultCacheCompletion = 1 << 20
+
+// WithCacheCompletion sets how many unread bytes of a download NewCachedParser still reads
+// when the file is closed early, to store the complete file in the cache. The default is 1 MiB.
+// Close blocks until these bytes are read. Zero closes at once, but then archives are
+// never cached, since the parsing stops before their central directory at the end.
+func WithCacheCompletion(limit int64) Option {
+	return func(cl *client) {
+		cl.cacheCompletion = limit
+	}
+}
+
+type cacheMeta struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// NewCachedParser returns a parser that downloads the dump files like NewParser
+// and stores a copy of every completely read file in dir, the postal codes in dir/zip.
+// The next request for the same file is sent with If-None-Match and
+// If-Modified-Since headers, and an unchanged file is read from dir.
+//
+// A file closed before it has been read completely, e.g. when the handler returns ErrStop,
+// is only cached if the rest of it is at most 1 MiB or the size set by WithCacheCompletion.
+// Close reads that rest before it returns, so it can take as long as downloading it.
+func NewCachedParser(dir string, options ...Option) Parser {
+	c := newClient(options)
+	local := NewDirParser(dir)
+
+	return Parser(func(ctx context.Context, file string) (io.ReadCloser, error) {
+		path, err := localPath(dir, file)
+		if err != nil {
+			return nil, err
+		}
+
+		header := http.Header{}
+		if meta := readCacheMeta(path); meta != nil {
+			if meta.ETag != "" {
+				header.Set("If-None-Match", meta.ETag)
+			}
+			if meta.LastModified != "" {
+				header.Set("If-Modified-Since", meta.LastModified)
+			}
+		}
+
+		resp, err := c.get(ctx, file, header)
+		if err != nil {
+			return nil, err
+		}
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+			return newCacheWriter(resp, c.resumable(ctx, file, resp), path, c.cacheCompletion)
+		case http.StatusNotModified:
+			resp.Body.Close()
+			return local(ctx, file)
+		default:
+			resp.Body.Close()
+			return nil, statusError(resp.Request.URL.String(), resp.StatusCode)
+		}
+	})
+}
+
+// readCacheMeta returns the validators of a cached file,
+// or nil if the file is not cached.
+func readCacheMeta(path string) *cacheMeta {
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+
+	data, err := os.ReadFile(path + ".meta")
+	if err != nil {
+		return nil
+	}
+
+	meta := &cacheMeta{}
+	if err := json.Unmarshal(data, meta); err != nil {
+		return nil
+	}
+	return meta
+}
+
+// cacheWriter tees a response body into a temporary file
+// and moves it into the cache once the body has been read completely.
+type cacheWriter struct {
+	body      io.ReadCloser
+	tmp       *os.File
+	path      string
+	meta      cacheMeta
+	remaining int64
+	complete  bool
+	limit     int64
+}
+
+func newCacheWriter(resp *http.Response, body io.ReadCloser, path string, limit int64) (*cacheWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		body.Close()
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		body.Close()
+		return nil, err
+	}
+
+	return &cacheWriter{
+		body: body,
+		tmp:  tmp,
+		path: path,
+		meta: cacheMeta{
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+		},
+		remaining: resp.ContentLength,
+		limit:     limit,
+	}, nil
+}
+
+func (w *cacheWriter) Read(p []byte) (int, error) {
+	n, err := w.body.Read(p)
+	if n > 0 {
+		w.remaining -= int64(n)
+		if w.tmp != nil {
+			if _, err := w.tmp.Write(p[:n]); err != nil {
+				w.discard()
+			}
+		}
+	}
+	if err == io.EOF {
+		w.complete = true
+		w.commit()
+	}
+	return n, err
+}
+
+func (w *cacheWriter) Close() error {
+	if !w.complete && w.tmp != nil && w.remaining >= 0 && w.remaining <= w.limit {
+		io.Copy(io.Discard, w)
+	}
+	err := w.body.Close()
+	w.discard()
+	return err
+}
+
+func (w *cacheWriter) commit() {
+	if w.tmp == nil {
+		return
+	}
+
+	tmp := w.tmp
+	w.tmp = nil
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	os.Remove(w.path + ".meta")
+	if err := os.Rename(tmp.Name(), w.path); err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+
+	if w.meta.ETag == "" && w.meta.LastModified == "" {
+		return
+	}
+	if data, err := json.Marshal(w.meta); err == nil {
+		os.WriteFile(w.path+".meta", data, 0644)
+	}
+}
+
+func (w *cacheWriter) discard() {
+	if w.tmp == nil {
+		return
+	}
+
+	w.tmp.Close()
+	os.Remove(w.tmp.Name())
+	w.tmp = nil
+}
@@ -0,0 +1,164 @@
+package geonames
+
+import (
+	"context"
+	"errors"
+	"github.com/mkrou/geonames/models"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCachedParser(t *testing.T) {
+	Convey("Given a cached parser over a dump server", t, func() {
+		etag := `"v1"`
+		archive := zipped("cities500.txt", geonameRows)
+		downloads, revalidations := 0, 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/cities500.zip" {
+				http.NotFound(w, r)
+				return
+			}
+			if r.Header.Get("If-None-Match") == etag {
+				revalidations++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			downloads++
+			w.Header().Set("ETag", etag)
+			w.Header().Set("Last-Modified", "Sat, 03 Nov 2012 00:00:00 GMT")
+			w.Write(archive)
+		}))
+		defer srv.Close()
+
+		dir := mirror(t, nil)
+		p := NewCachedParser(dir, WithBaseUrl(srv.URL))
+
+		count := func() (int, error) {
+			n := 0
+			err := p.GetGeonames(Cities500, func(x *models.Geoname) error {
+				n++
+				return nil
+			})
+			return n, err
+		}
+
+		Convey("When an archive is parsed for the first time", func() {
+			n, err := count()
+
+			Convey("It should be downloaded and stored in the cache", func() {
+				So(err, ShouldBeNil)
+				So(n, ShouldEqual, 3)
+				So(downloads, ShouldEqual, 1)
+				So(filepath.Join(dir, "cities500.zip"), shouldBeFile)
+				So(filepath.Join(dir, "cities500.zip.meta"), shouldBeFile)
+			})
+
+			Convey("And it is parsed again", func() {
+				n, err := count()
+
+				Convey("It should be revalidated and read from the cache", func() {
+					So(err, ShouldBeNil)
+					So(n, ShouldEqual, 3)
+					So(downloads, ShouldEqual, 1)
+					So(revalidations, ShouldEqual, 1)
+				})
+			})
+
+			Convey("And it has changed on the server", func() {
+				etag = `"v2"`
+				archive = zipped("cities500.txt", strings.SplitAfter(geonameRows, "\n")[0])
+				n, err := count()
+
+				Convey("It should be downloaded again", func() {
+					So(err, ShouldBeNil)
+					So(n, ShouldEqual, 1)
+					So(downloads, ShouldEqual, 2)
+					So(revalidations, ShouldEqual, 0)
+				})
+			})
+		})
+
+		Convey("When the parsing stops early", func() {
+			stop := func(p Parser) error {
+				return p.GetGeonames(Cities500, func(x *models.Geoname) error {
+					return ErrStop
+				})
+			}
+
+			Convey("The rest of a small file should be read to cache it", func() {
+				So(stop(p), ShouldBeNil)
+				So(filepath.Join(dir, "cities500.zip"), shouldBeFile)
+			})
+
+			Convey("A file should not be cached without a completion", func() {
+				archive = zipped("cities500.txt", geonameFixture(20000))
+				So(stop(NewCachedParser(dir, WithBaseUrl(srv.URL), WithCacheCompletion(0))), ShouldBeNil)
+				_, err := os.Stat(filepath.Join(dir, "cities500.zip"))
+				So(os.IsNotExist(err), ShouldBeTrue)
+			})
+		})
+
+		Convey("When a missing file is parsed", func() {
+			err := p.GetGeonames(Cities1000, func(x *models.Geoname) error {
+				return nil
+			})
+
+			Convey("The error should be ErrNotFound", func() {
+				So(errors.Is(err, ErrNotFound), ShouldBeTrue)
+			})
+		})
+	})
+}
+
+func shouldBeFile(actual interface{}, _ ...interface{}) string {
+	if _, err := os.Stat(actual.(string)); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+func TestCachedPostalCodes(t *testing.T) {
+	Convey("Given a cached parser over a server with the dump and the zip directories", t, func() {
+		var paths []string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			paths = append(paths, r.URL.Path)
+			w.Write(zipped("AD.txt", andorraPostalCodeRows))
+		}))
+		defer srv.Close()
+
+		root := mirror(t, nil)
+		dir := filepath.Join(root, "cache")
+		p := NewCachedParser(dir, WithBaseUrl(srv.URL+"/export/dump"))
+
+		Convey("When the postal codes of a country are parsed", func() {
+			n := 0
+			err := p.GetPostalCodes(CountryPostalCodes("AD"), func(c *models.PostalCode) error {
+				n++
+				return nil
+			})
+
+			Convey("They should be cached in the zip directory inside the cache", func() {
+				So(err, ShouldBeNil)
+				So(n, ShouldEqual, 1)
+				So(paths, ShouldResemble, []string{"/export/zip/AD.zip"})
+				So(filepath.Join(dir, "zip", "AD.zip"), shouldBeFile)
+				_, err := os.Stat(filepath.Join(root, "zip"))
+				So(os.IsNotExist(err), ShouldBeTrue)
+			})
+		})
+
+		Convey("When a file outside of the cache is requested", func() {
+			_, err := p(context.Background(), "../../AD.zip")
+
+			Convey("It should be rejected before it is downloaded", func() {
+				So(err, ShouldNotBeNil)
+				So(paths, ShouldBeEmpty)
+			})
+		})
+	})
+}
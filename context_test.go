@@ -0,0 +1,62 @@
+package geonames
+
+import (
+	"context"
+	"errors"
+	"github.com/mkrou/geonames/models"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParserContext(t *testing.T) {
+	Convey("Given a parser over a local mirror", t, func() {
+		dir := mirror(t, map[string][]byte{
+			"cities500.zip": zipped("cities500.txt", geonameRows),
+		})
+		p := NewDirParser(dir)
+
+		Convey("When the context is cancelled by the first handler call", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			count := 0
+			err := p.GetGeonamesContext(ctx, Cities500, func(x *models.Geoname) error {
+				count++
+				cancel()
+				return nil
+			})
+
+			Convey("The stream should stop before the next record", func() {
+				So(errors.Is(err, context.Canceled), ShouldBeTrue)
+				So(count, ShouldEqual, 1)
+			})
+		})
+	})
+
+	Convey("Given a dump server that stalls in the middle of a body", t, func() {
+		archive := zipped("cities500.txt", geonameRows)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write(archive[:len(archive)/2])
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}))
+		defer srv.Close()
+
+		dir := mirror(t, nil)
+		p := NewCachedParser(dir, WithBaseUrl(srv.URL))
+
+		Convey("When the deadline is exceeded", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			err := p.GetGeonamesContext(ctx, Cities500, func(x *models.Geoname) error {
+				return nil
+			})
+
+			Convey("The error should be the deadline", func() {
+				So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+			})
+		})
+	})
+}
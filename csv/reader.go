@@ -86,6 +86,9 @@ type Reader struct {
 	// numLine is the current line being read in the CSV file.
 	numLine int
 
+	// recordLine is the line where the most recently read record starts.
+	recordLine int
+
 	// rawBuffer is a line buffer only used by the readLine method.
 	rawBuffer []byte
 
@@ -147,6 +150,11 @@ func (r *Reader) ReadAll() (records [][]string, err error) {
 	}
 }
 
+// Line returns the 1-indexed line where the most recently read record starts.
+func (r *Reader) Line() int {
+	return r.recordLine
+}
+
 // readLine reads the next line (with the trailing endline).
 // If EOF is hit without a trailing endline, it will be omitted.
 // If some bytes were read, then the error is never io.EOF.
@@ -219,6 +227,7 @@ func (r *Reader) readRecord(dst []string) ([]string, error) {
 	var err error
 	commaLen := utf8.RuneLen(r.Comma)
 	recLine := r.numLine // Starting line for record
+	r.recordLine = recLine
 	r.recordBuffer = r.recordBuffer[:0]
 	r.fieldIndexes = r.fieldIndexes[:0]
 parseField:
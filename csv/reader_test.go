@@ -294,6 +294,20 @@ x,,,
 	}
 }
 
+func TestLine(t *testing.T) {
+	r := NewReader(strings.NewReader("#comment\na,b\n\nc,d\n"))
+	r.Comment = '#'
+
+	for _, want := range []int{2, 4} {
+		if _, err := r.Read(); err != nil {
+			t.Fatalf("Read() error: %v", err)
+		}
+		if got := r.Line(); got != want {
+			t.Errorf("Line() = %d, want %d", got, want)
+		}
+	}
+}
+
 // nTimes is an io.Reader which yields the string s n times.
 type nTimes struct {
 	s   string
@@ -0,0 +1,50 @@
+package geonames
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// NewDirParser returns a parser that reads the dump files from a local mirror
+// of the dump directory instead of downloading them. File names are resolved
+// relative to dir, so "alternatenames/AD.zip" is read from dir/alternatenames/AD.zip.
+// The postal codes such as "../zip/AD.zip" are read from dir/zip/AD.zip.
+func NewDirParser(dir string) Parser {
+	return Parser(func(ctx context.Context, file string) (io.ReadCloser, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		path, err := localPath(dir, file)
+		if err != nil {
+			return nil, err
+		}
+		f, err := os.Open(path)
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("File %s %w", path, ErrNotFound)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return f, nil
+	})
+}
+
+// localPath returns the path of a dump file in dir. The postal codes published
+// in the zip directory next to the dump directory are kept in dir/zip,
+// and any other file outside of dir is rejected.
+func localPath(dir, file string) (string, error) {
+	name := filepath.FromSlash(strings.TrimPrefix(file, "../zip/"))
+	if name != filepath.FromSlash(file) {
+		name = filepath.Join("zip", name)
+	}
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("File %s is outside of %s", file, dir)
+	}
+	return filepath.Join(dir, name), nil
+}
@@ -0,0 +1,68 @@
+package geonames
+
+import (
+	"errors"
+	"github.com/mkrou/geonames/models"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestDirParser(t *testing.T) {
+	Convey("Given a parser over a local mirror", t, func() {
+		dir := mirror(t, map[string][]byte{
+			"cities500.zip":                 zipped("cities500.txt", geonameRows),
+			"alternatenames/AD.zip":         zipped("AD.txt", alternateNameRows),
+			Deletes.WithLastDate().String(): []byte(deleteRows),
+		})
+		p := NewDirParser(dir)
+
+		Convey("When geonames are parsed", func() {
+			var names []string
+			err := p.GetGeonames(Cities500, func(x *models.Geoname) error {
+				names = append(names, x.Name)
+				return nil
+			})
+
+			Convey("The error should be nil", func() {
+				So(err, ShouldBeNil)
+			})
+
+			Convey("All rows should be read", func() {
+				So(names, ShouldResemble, []string{"El Tarter", "Sant Julià de Lòria", "Pas de la Casa"})
+			})
+		})
+
+		Convey("When alternate names are parsed from a subdirectory", func() {
+			count := 0
+			err := p.GetAlternateNames("alternatenames/AD.zip", func(x *models.AlternateName) error {
+				count++
+				return nil
+			})
+
+			So(err, ShouldBeNil)
+			So(count, ShouldEqual, 2)
+		})
+
+		Convey("When dated deletes are parsed", func() {
+			var x *models.GeonameDelete
+			err := p.GetDeletes(func(d *models.GeonameDelete) error {
+				x = d
+				return nil
+			})
+
+			So(err, ShouldBeNil)
+			So(x.Id, ShouldEqual, 3039154)
+			So(x.Comment, ShouldEqual, "duplicate")
+		})
+
+		Convey("When a missing file is parsed", func() {
+			err := p.GetGeonames(Cities1000, func(x *models.Geoname) error {
+				return nil
+			})
+
+			Convey("The error should be ErrNotFound", func() {
+				So(errors.Is(err, ErrNotFound), ShouldBeTrue)
+			})
+		})
+	})
+}
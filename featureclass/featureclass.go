@@ -0,0 +1,60 @@
+// Package featureclass describes the feature classes and codes of the geonames,
+// see http://www.geonames.org/export/codes.html.
+package featureclass
+
+import "strings"
+
+// Class is the one letter feature class of a geoname.
+type Class string
+
+const (
+	Administrative Class = "A" // country, state, region, ...
+	Hydrographic   Class = "H" // stream, lake, ...
+	Area           Class = "L" // parks, area, ...
+	PopulatedPlace Class = "P" // city, village, ...
+	Road           Class = "R" // road, railroad
+	Spot           Class = "S" // spot, building, farm
+	Hypsographic   Class = "T" // mountain, hill, rock, ...
+	Undersea       Class = "U" // undersea
+	Vegetation     Class = "V" // forest, heath, ...
+)
+
+// Classes are all the feature classes in alphabetical order.
+var Classes = []Class{Administrative, Hydrographic, Area, PopulatedPlace, Road, Spot, Hypsographic, Undersea, Vegetation}
+
+var descriptions = map[Class]string{
+	Administrative: "country, state, region,...",
+	Hydrographic:   "stream, lake, ...",
+	Area:           "parks,area, ...",
+	PopulatedPlace: "city, village,...",
+	Road:           "road, railroad",
+	Spot:           "spot, building, farm",
+	Hypsographic:   "mountain,hill,rock,...",
+	Undersea:       "undersea",
+	Vegetation:     "forest,heath,...",
+}
+
+// Valid reports whether c is one of Classes.
+func (c Class) Valid() bool {
+	_, ok := descriptions[c]
+	return ok
+}
+
+// Description returns the english description of the class or "" for an unknown class.
+func (c Class) Description() string {
+	return descriptions[c]
+}
+
+func (c Class) String() string {
+	return string(c)
+}
+
+// Split splits a code of the feature codes files such as "P.PPL" into its class and code.
+// A code without a class is returned with an empty class.
+func Split(code string) (Class, string) {
+	class, code, ok := strings.Cut(code, ".")
+	if !ok {
+		return "", class
+	}
+	return Class(class), code
+}
@@ -0,0 +1,53 @@
+package featureclass
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	Convey("Given codes of the feature codes files", t, func() {
+		Convey("The class should be split from the code", func() {
+			class, code := Split("P.PPL")
+			So(class, ShouldEqual, PopulatedPlace)
+			So(code, ShouldEqual, "PPL")
+		})
+
+		Convey("A code without a class should have an empty class", func() {
+			class, code := Split("null")
+			So(class, ShouldEqual, Class(""))
+			So(code, ShouldEqual, "null")
+		})
+	})
+
+	Convey("Given the classes", t, func() {
+		Convey("Every class should be valid and described", func() {
+			for _, c := range Classes {
+				So(c.Valid(), ShouldBeTrue)
+				So(c.Description(), ShouldNotBeEmpty)
+			}
+			So(Class("X").Valid(), ShouldBeFalse)
+		})
+	})
+}
+
+func TestRegistry(t *testing.T) {
+	Convey("Given a registry with a code in two languages", t, func() {
+		r := NewRegistry()
+		r.Add("en", PopulatedPlace, "PPL", Description{Name: "populated place"})
+		r.Add("ru", PopulatedPlace, "PPL", Description{Name: "населенный пункт"})
+
+		Convey("The description should be found in every language", func() {
+			d, ok := r.Lookup(PopulatedPlace, "PPL", "ru")
+			So(ok, ShouldBeTrue)
+			So(d.Name, ShouldEqual, "населенный пункт")
+			So(len(r.Descriptions(PopulatedPlace, "PPL")), ShouldEqual, 2)
+			So(r.Len(), ShouldEqual, 1)
+		})
+
+		Convey("A missing language should not be found", func() {
+			_, ok := r.Lookup(PopulatedPlace, "PPL", "sv")
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
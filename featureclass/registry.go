@@ -0,0 +1,48 @@
+package featureclass
+
+// Description is the name and the description of a feature code in a language.
+type Description struct {
+	Name        string
+	Description string
+}
+
+type key struct {
+	class Class
+	code  string
+}
+
+// Registry joins the descriptions of the feature codes in several languages.
+// The zero value is not usable, use NewRegistry.
+type Registry struct {
+	codes map[key]map[string]Description
+}
+
+func NewRegistry() *Registry {
+	return &Registry{codes: map[key]map[string]Description{}}
+}
+
+// Add sets the description of the feature code in the language, e.g. "en".
+func (r *Registry) Add(language string, class Class, code string, d Description) {
+	k := key{class, code}
+	if r.codes[k] == nil {
+		r.codes[k] = map[string]Description{}
+	}
+	r.codes[k][language] = d
+}
+
+// Lookup returns the description of the feature code in the language.
+func (r *Registry) Lookup(class Class, code, language string) (Description, bool) {
+	d, ok := r.codes[key{class, code}][language]
+	return d, ok
+}
+
+// Descriptions returns the descriptions of the feature code by their languages.
+// The map must not be modified.
+func (r *Registry) Descriptions(class Class, code string) map[string]Description {
+	return r.codes[key{class, code}]
+}
+
+// Len returns the number of feature codes in the registry.
+func (r *Registry) Len() int {
+	return len(r.codes)
+}
@@ -0,0 +1,40 @@
+package geonames
+
+import (
+	"context"
+	"github.com/mkrou/geonames/featureclass"
+	"github.com/mkrou/geonames/models"
+)
+
+// FeatureCodeFiles are the feature codes files in every language of the dump.
+var FeatureCodeFiles = []models.FeatureCodeFile{
+	FeatureCodeBg,
+	FeatureCodeEn,
+	FeatureCodeNb,
+	FeatureCodeNn,
+	FeatureCodeNo,
+	FeatureCodeRu,
+	FeatureCodeSv,
+}
+
+// LoadFeatureCodes joins the feature codes files into a registry
+// of the descriptions by the language of the file. Without files, all FeatureCodeFiles are loaded.
+func (p Parser) LoadFeatureCodes(ctx context.Context, files ...models.FeatureCodeFile) (*featureclass.Registry, error) {
+	if len(files) == 0 {
+		files = FeatureCodeFiles
+	}
+
+	r := featureclass.NewRegistry()
+	for _, file := range files {
+		language := file.Language()
+		err := p.GetFeatureCodesContext(ctx, file, func(f *models.FeatureCode) error {
+			class, code := f.Split()
+			r.Add(language, class, code, featureclass.Description{Name: f.Name, Description: f.Description})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,46 @@
+package geonames
+
+import (
+	"context"
+	"errors"
+	"github.com/mkrou/geonames/featureclass"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestLoadFeatureCodes(t *testing.T) {
+	Convey("Given a local mirror with feature codes in two languages", t, func() {
+		dir := mirror(t, map[string][]byte{
+			"featureCodes_en.txt": []byte("P.PPL\tpopulated place\ta city, town, village, or other agglomeration of buildings where people live and work\n" +
+				"A.ADM1\tfirst-order administrative division\ta primary administrative division of a country, such as a state in the United States\n"),
+			"featureCodes_ru.txt": []byte("P.PPL\tнаселенный пункт\tгород, поселок, деревня\n"),
+		})
+		p := NewDirParser(dir)
+
+		Convey("When they are loaded", func() {
+			r, err := p.LoadFeatureCodes(context.Background(), FeatureCodeEn, FeatureCodeRu)
+
+			Convey("The descriptions should be joined by the code", func() {
+				So(err, ShouldBeNil)
+				So(r.Len(), ShouldEqual, 2)
+
+				d, ok := r.Lookup(featureclass.PopulatedPlace, "PPL", "ru")
+				So(ok, ShouldBeTrue)
+				So(d.Name, ShouldEqual, "населенный пункт")
+				So(len(r.Descriptions(featureclass.PopulatedPlace, "PPL")), ShouldEqual, 2)
+
+				d, ok = r.Lookup(featureclass.Administrative, "ADM1", "en")
+				So(ok, ShouldBeTrue)
+				So(d.Name, ShouldEqual, "first-order administrative division")
+			})
+		})
+
+		Convey("When all languages are loaded", func() {
+			_, err := p.LoadFeatureCodes(context.Background())
+
+			Convey("The missing files should fail", func() {
+				So(errors.Is(err, ErrNotFound), ShouldBeTrue)
+			})
+		})
+	})
+}
@@ -0,0 +1,86 @@
+package geonames
+
+import (
+	"context"
+	"fmt"
+	"github.com/mkrou/geonames/internal/fixture"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const geonameRows = "3039154\tEl Tarter\tEl Tarter\tEhl'-Tarter,El Tarter\t42.57952\t1.65362\tP\tPPL\tAD\t\t02\t\t\t\t1052\t\t1721\tEurope/Andorra\t2012-11-03\n" +
+	"3039163\tSant Julià de Lòria\tSant Julia de Loria\tSant Julia de Loria\t42.46372\t1.49129\tP\tPPLA\tAD\t\t06\t\t\t\t8022\t\t921\tEurope/Andorra\t2013-11-23\n" +
+	"3039604\tPas de la Casa\tPas de la Casa\tPas de la Kasa\t42.54277\t1.73361\tP\tPPL\tAD\t\t03\t\t\t\t2363\t2050\t2106\tEurope/Andorra\t2008-06-09\n"
+
+const alternateNameRows = "1\t3039154\ten\tEl Tarter\t1\t\t\t\t\t\n" +
+	"2\t3039154\tru\tЭль-Тартер\t\t\t\t\t\t\n"
+
+const deleteRows = "3039154\tEl Tarter\tduplicate\n"
+
+// The archives and mirrors of the tests are built by the fixture package.
+var (
+	geonameFixture = fixture.Geonames
+	zipped         = fixture.Zip
+	mirror         = fixture.Dir
+)
+
+// tracker wraps a parser and keeps every file it opened until the file is closed.
+type tracker struct {
+	mu     sync.Mutex
+	opened int
+	open   map[*trackedFile]string
+}
+
+type trackedFile struct {
+	io.ReadCloser
+	tracker *tracker
+}
+
+func (f *trackedFile) Close() error {
+	f.tracker.mu.Lock()
+	delete(f.tracker.open, f)
+	f.tracker.mu.Unlock()
+	return f.ReadCloser.Close()
+}
+
+// track returns a parser that records the files opened by p in t.
+func track(p Parser) (*tracker, Parser) {
+	t := &tracker{open: map[*trackedFile]string{}}
+
+	return t, Parser(func(ctx context.Context, file string) (io.ReadCloser, error) {
+		r, err := p(ctx, file)
+		if err != nil {
+			return nil, err
+		}
+
+		f := &trackedFile{ReadCloser: r, tracker: t}
+		t.mu.Lock()
+		t.opened++
+		t.open[f] = file
+		t.mu.Unlock()
+		return f, nil
+	})
+}
+
+// shouldHaveClosedAll asserts that every file opened through a tracker was closed.
+func shouldHaveClosedAll(actual interface{}, _ ...interface{}) string {
+	t := actual.(*tracker)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.opened == 0 {
+		return "Expected the parser to open a file, but it opened nothing"
+	}
+	if len(t.open) == 0 {
+		return ""
+	}
+
+	var leaks []string
+	for _, file := range t.open {
+		leaks = append(leaks, file)
+	}
+	sort.Strings(leaks)
+	return fmt.Sprintf("Expected all opened files to be closed, but %s were not", strings.Join(leaks, ", "))
+}
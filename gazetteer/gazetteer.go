@@ -0,0 +1,210 @@
+// Package gazetteer keeps the geonames, the countries and the administrative divisions
+// of the dump in memory for fast lookups.
+package gazetteer
+
+import (
+	"context"
+	"github.com/mkrou/geonames"
+	"github.com/mkrou/geonames/internal/intern"
+	"github.com/mkrou/geonames/models"
+	"github.com/mkrou/geonames/stream"
+	"iter"
+	"strings"
+)
+
+// Option configures what Load keeps in memory.
+type Option func(*config)
+
+// WithAlternateNames keeps the alternate names of the geonames, which are skipped by default
+// because they take more memory than all the other columns together.
+func WithAlternateNames() Option {
+	return func(c *config) {
+		c.alternateNames = true
+	}
+}
+
+// WithArchive loads the geonames from the archive instead of geonames.Cities500.
+func WithArchive(archive models.GeoNameFile) Option {
+	return func(c *config) {
+		c.archive = archive
+	}
+}
+
+type config struct {
+	archive        models.GeoNameFile
+	alternateNames bool
+}
+
+// chunkSize is the number of geonames in a chunk of the store. The store grows by chunks,
+// so the geonames are never copied to a larger slice, which would need twice their memory.
+const chunkSize = 4096
+
+// Gazetteer is an immutable in-memory store of the dump.
+// It is safe for concurrent use. The returned values point into the store and must not be modified.
+type Gazetteer struct {
+	geonames  [][]models.Geoname // chunks of chunkSize geonames, the last one may be shorter
+	count     int
+	ids       map[int]int32
+	countries map[string]*models.Country
+	iso3      map[string]*models.Country
+	admin1    map[string]*models.AdminDivision
+	admin2    map[string]*models.AdminSubdivision
+}
+
+// Load reads the geonames, the countries and the administrative divisions with p.
+func Load(ctx context.Context, p geonames.Parser, options ...Option) (*Gazetteer, error) {
+	c := &config{archive: geonames.Cities500}
+	for _, option := range options {
+		option(c)
+	}
+
+	g := &Gazetteer{
+		ids:       map[int]int32{},
+		countries: map[string]*models.Country{},
+		iso3:      map[string]*models.Country{},
+		admin1:    map[string]*models.AdminDivision{},
+		admin2:    map[string]*models.AdminSubdivision{},
+	}
+	in := intern.Strings{}
+
+	var streamOptions []stream.Option
+	if !c.alternateNames {
+		streamOptions = append(streamOptions, stream.SkipColumns("alternatenames"))
+	}
+	err := geonames.StreamContext(ctx, p, models.DumpFile(c.archive), func(x *models.Geoname) error {
+		x.Name = strings.Clone(x.Name)
+		x.AsciiName = strings.Clone(x.AsciiName)
+		for i, name := range x.AlternateNames {
+			x.AlternateNames[i] = strings.Clone(name)
+		}
+		x.Class = in.Intern(x.Class)
+		x.Code = in.Intern(x.Code)
+		x.CountryCode = in.Intern(x.CountryCode)
+		x.Admin1Code = in.Intern(x.Admin1Code)
+		x.Admin2Code = in.Intern(x.Admin2Code)
+		x.Admin3Code = in.Intern(x.Admin3Code)
+		x.Admin4Code = in.Intern(x.Admin4Code)
+		x.Timezone = in.Intern(x.Timezone)
+		for i, code := range x.AlternateCountryCodes {
+			x.AlternateCountryCodes[i] = in.Intern(code)
+		}
+
+		g.ids[x.Id] = int32(g.count)
+		g.add(x)
+		return nil
+	}, streamOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.GetCountriesContext(ctx, func(x *models.Country) error {
+		g.countries[x.Iso2Code] = x
+		g.iso3[x.Iso3Code] = x
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.GetAdminDivisionsContext(ctx, func(x *models.AdminDivision) error {
+		g.admin1[x.Code] = x
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.GetAdminSubdivisionsContext(ctx, func(x *models.AdminSubdivision) error {
+		g.admin2[x.Code] = x
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+func (g *Gazetteer) add(x *models.Geoname) {
+	if g.count%chunkSize == 0 {
+		g.geonames = append(g.geonames, make([]models.Geoname, 0, chunkSize))
+	}
+	chunk := &g.geonames[len(g.geonames)-1]
+	*chunk = append(*chunk, *x)
+	g.count++
+}
+
+func (g *Gazetteer) at(i int) *models.Geoname {
+	return &g.geonames[i/chunkSize][i%chunkSize]
+}
+
+// Len returns the number of geonames.
+func (g *Gazetteer) Len() int {
+	return g.count
+}
+
+// Geoname returns the geoname with the id.
+func (g *Gazetteer) Geoname(id int) (*models.Geoname, bool) {
+	i, ok := g.ids[id]
+	if !ok {
+		return nil, false
+	}
+	return g.at(int(i)), true
+}
+
+// All returns the geonames in the order of the archive.
+func (g *Gazetteer) All() iter.Seq[*models.Geoname] {
+	return func(yield func(*models.Geoname) bool) {
+		for _, chunk := range g.geonames {
+			for i := range chunk {
+				if !yield(&chunk[i]) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Country returns the country with the ISO-3166 2-letter or 3-letter code, e.g. "AD" or "AND".
+func (g *Gazetteer) Country(code string) (*models.Country, bool) {
+	code = strings.ToUpper(code)
+	if len(code) == 3 {
+		c, ok := g.iso3[code]
+		return c, ok
+	}
+	c, ok := g.countries[code]
+	return c, ok
+}
+
+// Admin1 returns the first-order administrative division with the concatenated code, e.g. "US.CA".
+func (g *Gazetteer) Admin1(code string) (*models.AdminDivision, bool) {
+	d, ok := g.admin1[code]
+	return d, ok
+}
+
+// Admin2 returns the second-order administrative division with the concatenated code, e.g. "US.CA.037".
+func (g *Gazetteer) Admin2(code string) (*models.AdminSubdivision, bool) {
+	d, ok := g.admin2[code]
+	return d, ok
+}
+
+// CountryOf returns the country of the geoname.
+func (g *Gazetteer) CountryOf(x *models.Geoname) (*models.Country, bool) {
+	return g.Country(x.CountryCode)
+}
+
+// Admin1Of returns the first-order administrative division of the geoname.
+func (g *Gazetteer) Admin1Of(x *models.Geoname) (*models.AdminDivision, bool) {
+	if x.Admin1Code == "" {
+		return nil, false
+	}
+	return g.Admin1(x.CountryCode + "." + x.Admin1Code)
+}
+
+// Admin2Of returns the second-order administrative division of the geoname.
+func (g *Gazetteer) Admin2Of(x *models.Geoname) (*models.AdminSubdivision, bool) {
+	if x.Admin2Code == "" {
+		return nil, false
+	}
+	return g.Admin2(x.CountryCode + "." + x.Admin1Code + "." + x.Admin2Code)
+}
@@ -0,0 +1,172 @@
+package gazetteer
+
+import (
+	"context"
+	"github.com/mkrou/geonames"
+	"github.com/mkrou/geonames/internal/fixture"
+	"github.com/mkrou/geonames/models"
+	. "github.com/smartystreets/goconvey/convey"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"unsafe"
+)
+
+const geonameRows = "3039154\tEl Tarter\tEl Tarter\tEhl'-Tarter,El Tarter\t42.57952\t1.65362\tP\tPPL\tAD\t\t02\t\t\t\t1052\t\t1721\tEurope/Andorra\t2012-11-03\n" +
+	"3039163\tSant Julià de Lòria\tSant Julia de Loria\tSant Julia de Loria\t42.46372\t1.49129\tP\tPPLA\tAD\t\t06\t\t\t\t8022\t\t921\tEurope/Andorra\t2013-11-23\n" +
+	"5368361\tLos Angeles\tLos Angeles\t\t34.05223\t-118.24368\tP\tPPLA2\tUS\t\tCA\t037\t\t\t3971883\t89\t115\tAmerica/Los_Angeles\t2019-09-05\n"
+
+const countryRows = "#ISO\tISO3\tISO-Numeric\tfips\tCountry\tCapital\tArea(in sq km)\tPopulation\tContinent\ttld\tCurrencyCode\tCurrencyName\tPhone\tPostal Code Format\tPostal Code Regex\tLanguages\tgeonameid\tneighbours\tEquivalentFipsCode\n" +
+	"AD\tAND\t020\tAN\tAndorra\tAndorra la Vella\t468\t77006\tEU\t.ad\tEUR\tEuro\t376\tAD###\t^(?:AD)*(\\d{3})$\tca\t3041565\tES,FR\t\n" +
+	"US\tUSA\t840\tUS\tUnited States\tWashington\t9629091\t327167434\tNA\t.us\tUSD\tDollar\t1\t#####-####\t^\\d{5}(-\\d{4})?$\ten-US,es-US,haw,fr\t6252001\tCA,MX,CU\t\n"
+
+const admin1Rows = "AD.02\tCanillo\tCanillo\t3041203\n" +
+	"US.CA\tCalifornia\tCalifornia\t5332921\n"
+
+const admin2Rows = "US.CA.037\tLos Angeles County\tLos Angeles County\t5368381\n"
+
+// mirror creates a dump directory with the countries, the divisions and the geonames.
+func mirror(t testing.TB, geonameRows string) string {
+	return fixture.Dir(t, map[string][]byte{
+		"cities500.zip":        fixture.Zip("cities500.txt", geonameRows),
+		"countryInfo.txt":      []byte(countryRows),
+		"admin1CodesASCII.txt": []byte(admin1Rows),
+		"admin2Codes.txt":      []byte(admin2Rows),
+	})
+}
+
+func TestLoad(t *testing.T) {
+	Convey("Given a local mirror", t, func() {
+		dir := mirror(t, geonameRows)
+		p := geonames.NewDirParser(dir)
+
+		Convey("When the gazetteer is loaded", func() {
+			g, err := Load(context.Background(), p)
+			So(err, ShouldBeNil)
+
+			Convey("The geonames should be found by their id", func() {
+				So(g.Len(), ShouldEqual, 3)
+				x, ok := g.Geoname(3039163)
+				So(ok, ShouldBeTrue)
+				So(x.Name, ShouldEqual, "Sant Julià de Lòria")
+
+				_, ok = g.Geoname(1)
+				So(ok, ShouldBeFalse)
+			})
+
+			Convey("The alternate names should be skipped", func() {
+				x, _ := g.Geoname(3039154)
+				So(x.AlternateNames, ShouldBeNil)
+			})
+
+			Convey("The countries should be found by both iso codes", func() {
+				c, ok := g.Country("AD")
+				So(ok, ShouldBeTrue)
+				So(c.Name, ShouldEqual, "Andorra")
+
+				c, ok = g.Country("usa")
+				So(ok, ShouldBeTrue)
+				So(c.Iso2Code, ShouldEqual, "US")
+			})
+
+			Convey("The divisions of a geoname should be found", func() {
+				x, _ := g.Geoname(5368361)
+				a1, ok := g.Admin1Of(x)
+				So(ok, ShouldBeTrue)
+				So(a1.Name, ShouldEqual, "California")
+
+				a2, ok := g.Admin2Of(x)
+				So(ok, ShouldBeTrue)
+				So(a2.Name, ShouldEqual, "Los Angeles County")
+
+				c, ok := g.CountryOf(x)
+				So(ok, ShouldBeTrue)
+				So(c.Name, ShouldEqual, "United States")
+			})
+
+			Convey("The repeated strings should be shared", func() {
+				a, _ := g.Geoname(3039154)
+				b, _ := g.Geoname(3039163)
+				So(unsafeData(a.Timezone), ShouldEqual, unsafeData(b.Timezone))
+			})
+
+			Convey("All geonames should be iterated in the order of the archive", func() {
+				var ids []int
+				for x := range g.All() {
+					ids = append(ids, x.Id)
+				}
+				So(ids, ShouldResemble, []int{3039154, 3039163, 5368361})
+			})
+		})
+
+		Convey("When the gazetteer is loaded with the alternate names", func() {
+			g, err := Load(context.Background(), p, WithAlternateNames(), WithArchive(geonames.Cities500))
+			So(err, ShouldBeNil)
+
+			x, _ := g.Geoname(3039154)
+			So(x.AlternateNames, ShouldResemble, models.StringList{"Ehl'-Tarter", "El Tarter"})
+		})
+
+		Convey("When a file is missing", func() {
+			os.Remove(filepath.Join(dir, "admin2Codes.txt"))
+			_, err := Load(context.Background(), p)
+
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestChunks(t *testing.T) {
+	Convey("Given more geonames than a chunk", t, func() {
+		dir := mirror(t, fixture.Geonames(2*chunkSize+1))
+
+		g, err := Load(context.Background(), geonames.NewDirParser(dir))
+		So(err, ShouldBeNil)
+
+		Convey("Every geoname should be found in its chunk", func() {
+			So(g.Len(), ShouldEqual, 2*chunkSize+1)
+			for _, id := range []int{1, chunkSize, chunkSize + 1, 2*chunkSize + 1} {
+				x, ok := g.Geoname(id)
+				So(ok, ShouldBeTrue)
+				So(x.Id, ShouldEqual, id)
+			}
+
+			n := 0
+			for x := range g.All() {
+				n++
+				if x.Id != n {
+					break
+				}
+			}
+			So(n, ShouldEqual, 2*chunkSize+1)
+		})
+	})
+}
+
+// BenchmarkLoad loads as many geonames as cities500 has and reports the memory the gazetteer keeps.
+func BenchmarkLoad(b *testing.B) {
+	dir := mirror(b, fixture.Geonames(230000))
+	p := geonames.NewDirParser(dir)
+
+	var before, after runtime.MemStats
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		g, err := Load(context.Background(), p)
+		if err != nil {
+			b.Fatal(err)
+		}
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+		runtime.KeepAlive(g)
+	}
+	b.ReportMetric(float64(after.HeapAlloc-before.HeapAlloc)/(1<<20), "MiB-kept")
+	b.ReportMetric(float64(after.HeapSys)/(1<<20), "MiB-heap-sys")
+}
+
+func unsafeData(s string) *byte {
+	return unsafe.StringData(s)
+}
@@ -0,0 +1,72 @@
+package geometry
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrNoConvergence is returned by Vincenty for nearly antipodal points.
+var ErrNoConvergence = errors.New("geometry: vincenty formula failed to converge")
+
+// Haversine returns the great-circle distance between two coordinates in kilometers,
+// on a sphere with the EarthRadius.
+func Haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	dLat, dLon := radians(lat2-lat1), radians(lon2-lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(radians(lat1))*math.Cos(radians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// wgs84 ellipsoid
+const (
+	semiMajorAxis = 6378.137 // kilometers
+	flattening    = 1 / 298.257223563
+	semiMinorAxis = semiMajorAxis * (1 - flattening)
+)
+
+// Vincenty returns the distance between two coordinates in kilometers on the wgs84 ellipsoid.
+// It is accurate to less than a millimeter, but fails with ErrNoConvergence for nearly antipodal points.
+func Vincenty(lat1, lon1, lat2, lon2 float64) (float64, error) {
+	u1 := math.Atan((1 - flattening) * math.Tan(radians(lat1)))
+	u2 := math.Atan((1 - flattening) * math.Tan(radians(lat2)))
+	sinU1, cosU1 := math.Sincos(u1)
+	sinU2, cosU2 := math.Sincos(u2)
+
+	l := radians(lon2 - lon1)
+	lambda := l
+	var sinSigma, cosSigma, sigma, cos2Alpha, cos2SigmaM float64
+	for i := 0; ; i++ {
+		if i == 200 {
+			return 0, ErrNoConvergence
+		}
+
+		sinLambda, cosLambda := math.Sincos(lambda)
+		sinSigma = math.Hypot(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+		if sinSigma == 0 {
+			return 0, nil // the same point
+		}
+		cosSigma = sinU1*sinU2 + cosU1*cosU2*cosLambda
+		sigma = math.Atan2(sinSigma, cosSigma)
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cos2Alpha = 1 - sinAlpha*sinAlpha
+		cos2SigmaM = 0
+		if cos2Alpha != 0 {
+			cos2SigmaM = cosSigma - 2*sinU1*sinU2/cos2Alpha // 0 on the equator
+		}
+
+		c := flattening / 16 * cos2Alpha * (4 + flattening*(4-3*cos2Alpha))
+		previous := lambda
+		lambda = l + (1-c)*flattening*sinAlpha*(sigma+c*sinSigma*(cos2SigmaM+c*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+		if math.Abs(lambda-previous) < 1e-12 {
+			break
+		}
+	}
+
+	u2s := cos2Alpha * (semiMajorAxis*semiMajorAxis - semiMinorAxis*semiMinorAxis) / (semiMinorAxis * semiMinorAxis)
+	a := 1 + u2s/16384*(4096+u2s*(-768+u2s*(320-175*u2s)))
+	b := u2s / 1024 * (256 + u2s*(-128+u2s*(74-47*u2s)))
+	deltaSigma := b * sinSigma * (cos2SigmaM + b/4*(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-
+		b/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+
+	return semiMinorAxis * a * (sigma - deltaSigma), nil
+}
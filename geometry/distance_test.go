@@ -0,0 +1,51 @@
+package geometry
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	Convey("Given Paris and London", t, func() {
+		Convey("The haversine distance should be on the sphere", func() {
+			So(Haversine(48.85341, 2.3488, 51.50853, -0.12574), ShouldAlmostEqual, 343.9, 0.5)
+			So(Haversine(0, 179.5, 0, -179.5), ShouldAlmostEqual, 111.2, 0.1)
+		})
+
+		Convey("The vincenty distance should be on the ellipsoid", func() {
+			d, err := Vincenty(48.85341, 2.3488, 51.50853, -0.12574)
+			So(err, ShouldBeNil)
+			So(d, ShouldAlmostEqual, 344.5, 0.5)
+		})
+	})
+
+	Convey("Given the reference points of Vincenty's paper", t, func() {
+		// Flinders Peak to Buninyong, 54972.271 m
+		d, err := Vincenty(-37.95103342, 144.42486789, -37.65282114, 143.92649554)
+
+		So(err, ShouldBeNil)
+		So(d, ShouldAlmostEqual, 54.972271, 1e-6)
+	})
+
+	Convey("Given the same point", t, func() {
+		d, err := Vincenty(10, 20, 10, 20)
+
+		So(err, ShouldBeNil)
+		So(d, ShouldEqual, 0)
+	})
+
+	Convey("Given nearly antipodal points", t, func() {
+		_, err := Vincenty(0, 0, 0.5, 179.7)
+
+		So(err, ShouldEqual, ErrNoConvergence)
+	})
+
+	Convey("Given a box across the antimeridian", t, func() {
+		b := BBox{MinLon: 170, MinLat: -20, MaxLon: -170, MaxLat: -10}
+
+		So(b.CrossesAntimeridian(), ShouldBeTrue)
+		So(b.Contains(-15, 179), ShouldBeTrue)
+		So(b.Contains(-15, -175), ShouldBeTrue)
+		So(b.Contains(-15, 0), ShouldBeFalse)
+	})
+}
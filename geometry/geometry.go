@@ -0,0 +1,338 @@
+// Package geometry decodes the GeoJSON polygons of the shapes dump
+// and answers simple questions about them such as whether they contain a coordinate.
+package geometry
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// EarthRadius is the mean radius of the earth in kilometers.
+const EarthRadius = 6371.0088
+
+// Point is a coordinate in decimal degrees (wgs84).
+// It is decoded from a GeoJSON position, which has the longitude first.
+type Point struct {
+	Lon float64
+	Lat float64
+}
+
+func (p *Point) UnmarshalJSON(data []byte) error {
+	var position []float64
+	if err := json.Unmarshal(data, &position); err != nil {
+		return err
+	}
+	if len(position) < 2 {
+		return fmt.Errorf("geometry: position %s has less than 2 values", data)
+	}
+	p.Lon, p.Lat = position[0], position[1]
+	return nil
+}
+
+func (p Point) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]float64{p.Lon, p.Lat})
+}
+
+// Ring is a closed line of points, the last point is the same as the first one.
+type Ring []Point
+
+// Polygon is an outer ring followed by the rings of its holes.
+type Polygon []Ring
+
+// MultiPolygon is a list of polygons such as a country with islands.
+type MultiPolygon []Polygon
+
+// Decode decodes a GeoJSON Polygon or MultiPolygon geometry.
+// A Polygon is returned as a MultiPolygon of one polygon.
+func Decode(data []byte) (MultiPolygon, error) {
+	var g struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	}
+	if err := json.Unmarshal(data, &g); err != nil {
+		return nil, err
+	}
+
+	switch g.Type {
+	case "Polygon":
+		var p Polygon
+		if err := json.Unmarshal(g.Coordinates, &p); err != nil {
+			return nil, err
+		}
+		return MultiPolygon{p}, nil
+	case "MultiPolygon":
+		var m MultiPolygon
+		if err := json.Unmarshal(g.Coordinates, &m); err != nil {
+			return nil, err
+		}
+		return m, nil
+	default:
+		return nil, fmt.Errorf("geometry: unsupported type %q", g.Type)
+	}
+}
+
+// BBox is a bounding box in decimal degrees.
+type BBox struct {
+	MinLon, MinLat float64
+	MaxLon, MaxLat float64
+}
+
+// emptyBBox is extended by the first point to the box of the point.
+var emptyBBox = BBox{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+
+func (b BBox) extend(p Point) BBox {
+	return BBox{
+		MinLon: math.Min(b.MinLon, p.Lon),
+		MinLat: math.Min(b.MinLat, p.Lat),
+		MaxLon: math.Max(b.MaxLon, p.Lon),
+		MaxLat: math.Max(b.MaxLat, p.Lat),
+	}
+}
+
+// CrossesAntimeridian reports whether the box spans the 180th meridian,
+// which is given by a MinLon greater than the MaxLon, e.g. 170 to -170 for Fiji.
+func (b BBox) CrossesAntimeridian() bool {
+	return b.MinLon > b.MaxLon
+}
+
+// Contains reports whether the coordinate is within the box or on its border.
+func (b BBox) Contains(lat, lon float64) bool {
+	if lat < b.MinLat || lat > b.MaxLat {
+		return false
+	}
+	if b.CrossesAntimeridian() {
+		return lon >= b.MinLon || lon <= b.MaxLon
+	}
+	return lon >= b.MinLon && lon <= b.MaxLon
+}
+
+// BBox returns the bounding box of the outer ring.
+// The box of a ring across the antimeridian crosses it too, see CrossesAntimeridian.
+func (p Polygon) BBox() BBox {
+	return p.outer().bbox().wrap()
+}
+
+// BBox returns the bounding box of all the polygons. The polygons on both sides
+// of the antimeridian, e.g. of Fiji, have a box that crosses it.
+func (m MultiPolygon) BBox() BBox {
+	b := emptyBBox
+	for _, p := range m {
+		pb := p.outer().bbox()
+		if pb.MinLon > pb.MaxLon {
+			continue
+		}
+		if b.MinLon <= b.MaxLon {
+			d := shift(b.MinLon, pb.MinLon) - pb.MinLon
+			pb.MinLon, pb.MaxLon = pb.MinLon+d, pb.MaxLon+d
+		}
+		b = b.extend(Point{Lon: pb.MinLon, Lat: pb.MinLat}).extend(Point{Lon: pb.MaxLon, Lat: pb.MaxLat})
+	}
+	return b.wrap()
+}
+
+// bbox returns the box of the ring with unwrapped longitudes, which may be beyond ±180.
+func (r Ring) bbox() BBox {
+	b := emptyBBox
+	prev := 0.0
+	for i, p := range r {
+		if i > 0 {
+			p.Lon = unwrap(prev, p.Lon)
+		}
+		b = b.extend(p)
+		prev = p.Lon
+	}
+	return b
+}
+
+// wrap returns the box with the longitudes between -180 and 180,
+// crossing the antimeridian if it reaches beyond ±180.
+func (b BBox) wrap() BBox {
+	switch {
+	case b.MaxLon-b.MinLon >= 360:
+		b.MinLon, b.MaxLon = -180, 180
+	case b.MinLon < -180 || b.MaxLon > 180:
+		b.MinLon, b.MaxLon = math.Remainder(b.MinLon, 360), math.Remainder(b.MaxLon, 360)
+	}
+	return b
+}
+
+func (p Polygon) outer() Ring {
+	if len(p) == 0 {
+		return nil
+	}
+	return p[0]
+}
+
+// Area returns the area of the ring on the sphere in square kilometers.
+func (r Ring) Area() float64 {
+	if len(r) < 3 {
+		return 0
+	}
+
+	sum := 0.0
+	for i := range r {
+		p1, p2 := r[i], r[(i+1)%len(r)]
+		sum += radians(unwrap(p1.Lon, p2.Lon)-p1.Lon) * (2 + math.Sin(radians(p1.Lat)) + math.Sin(radians(p2.Lat)))
+	}
+	return math.Abs(sum * EarthRadius * EarthRadius / 2)
+}
+
+// Area returns the area of the polygon without its holes in square kilometers.
+func (p Polygon) Area() float64 {
+	area := 0.0
+	for i, r := range p {
+		if i == 0 {
+			area += r.Area()
+		} else {
+			area -= r.Area()
+		}
+	}
+	return area
+}
+
+// Area returns the area of all the polygons in square kilometers.
+func (m MultiPolygon) Area() float64 {
+	area := 0.0
+	for _, p := range m {
+		area += p.Area()
+	}
+	return area
+}
+
+// Centroid returns the center of mass of the polygons, computed on the plane of the coordinates.
+// This is a good approximation for polygons far from the poles. The longitudes of a polygon
+// crossing the antimeridian and of the polygons on both sides of it, e.g. of Fiji,
+// are taken as if they were contiguous, and the result is between -180 and 180.
+func (m MultiPolygon) Centroid() Point {
+	var lon, lat, area, ref float64
+	for _, p := range m {
+		for i, r := range p {
+			a, c := r.planar()
+			if a == 0 {
+				continue
+			}
+			if area == 0 {
+				ref = c.Lon
+			}
+			if i > 0 {
+				a = -a
+			}
+			lon += shift(ref, c.Lon) * a
+			lat += c.Lat * a
+			area += a
+		}
+	}
+	if area == 0 {
+		return Point{}
+	}
+	return Point{Lon: math.Remainder(lon/area, 360), Lat: lat / area}
+}
+
+// Centroid returns the center of mass of the polygon, see MultiPolygon.Centroid.
+func (p Polygon) Centroid() Point {
+	return MultiPolygon{p}.Centroid()
+}
+
+// planar returns the absolute area and the centroid of the ring on the plane of the coordinates.
+func (r Ring) planar() (float64, Point) {
+	if len(r) < 3 {
+		return 0, Point{}
+	}
+
+	var area, lon, lat float64
+	p1 := r[len(r)-1]
+	for _, p2 := range r {
+		p2.Lon = unwrap(p1.Lon, p2.Lon)
+		cross := p1.Lon*p2.Lat - p2.Lon*p1.Lat
+		area += cross
+		lon += (p1.Lon + p2.Lon) * cross
+		lat += (p1.Lat + p2.Lat) * cross
+		p1 = p2
+	}
+	if area == 0 {
+		return 0, Point{}
+	}
+	return math.Abs(area / 2), Point{Lon: lon / (3 * area), Lat: lat / (3 * area)}
+}
+
+// Contains reports whether the coordinate is inside the ring.
+// A ring crossing the antimeridian contains the coordinates on both sides of it.
+func (r Ring) Contains(lat, lon float64) bool {
+	inside, unwrapped := r.contains(lat, lon)
+	if inside || !unwrapped {
+		return inside
+	}
+	// the unwrapped ring reaches beyond ±180, where the coordinate is 360 degrees away
+	east, _ := r.contains(lat, lon+360)
+	west, _ := r.contains(lat, lon-360)
+	return east || west
+}
+
+// contains casts a ray from the coordinate along its latitude through the unwrapped ring
+// and reports whether it is inside and whether a longitude was unwrapped.
+func (r Ring) contains(lat, lon float64) (inside, unwrapped bool) {
+	if len(r) == 0 {
+		return false, false
+	}
+
+	a := r[len(r)-1]
+	for _, b := range r {
+		if l := unwrap(a.Lon, b.Lon); l != b.Lon {
+			b.Lon, unwrapped = l, true
+		}
+		if (a.Lat > lat) != (b.Lat > lat) && lon < (b.Lon-a.Lon)*(lat-a.Lat)/(b.Lat-a.Lat)+a.Lon {
+			inside = !inside
+		}
+		a = b
+	}
+	return inside, unwrapped
+}
+
+// Contains reports whether the coordinate is inside the outer ring and outside of the holes.
+func (p Polygon) Contains(lat, lon float64) bool {
+	if len(p) == 0 || !p[0].Contains(lat, lon) {
+		return false
+	}
+	for _, hole := range p[1:] {
+		if hole.Contains(lat, lon) {
+			return false
+		}
+	}
+	return true
+}
+
+// Contains reports whether the coordinate is inside one of the polygons.
+func (m MultiPolygon) Contains(lat, lon float64) bool {
+	for _, p := range m {
+		if p.Contains(lat, lon) {
+			return true
+		}
+	}
+	return false
+}
+
+// unwrap returns lon shifted by 360 degrees if it is more than 180 degrees away from prev,
+// so an edge crossing the antimeridian does not go around the earth. An edge along
+// the antimeridian, from 180 to -180 as in the rings around a pole, is kept.
+func unwrap(prev, lon float64) float64 {
+	if math.Abs(prev) == 180 && math.Abs(lon) == 180 {
+		return lon
+	}
+	return shift(prev, lon)
+}
+
+// shift returns lon shifted by a multiple of 360 degrees to be at most 180 degrees away from ref.
+func shift(ref, lon float64) float64 {
+	for lon-ref > 180 {
+		lon -= 360
+	}
+	for ref-lon > 180 {
+		lon += 360
+	}
+	return lon
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
@@ -0,0 +1,134 @@
+package geometry
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"math"
+	"testing"
+)
+
+// square is a polygon from 0,0 to 1,1 with a hole from 0.4,0.4 to 0.6,0.6.
+const square = `{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]],[[0.4,0.4],[0.6,0.4],[0.6,0.6],[0.4,0.6],[0.4,0.4]]]}`
+
+// islands are two squares around 10,10 and 20,20.
+const islands = `{"type":"MultiPolygon","coordinates":[[[[9,9],[11,9],[11,11],[9,11],[9,9]]],[[[19,19],[21,19],[21,21],[19,21],[19,19]]]]}`
+
+// fiji is a square from 178,-17 to -178,-15 across the antimeridian,
+// once as one polygon and once split at the antimeridian.
+const (
+	fiji      = `{"type":"Polygon","coordinates":[[[178,-17],[-178,-17],[-178,-15],[178,-15],[178,-17]]]}`
+	fijiSplit = `{"type":"MultiPolygon","coordinates":[[[[178,-17],[180,-17],[180,-15],[178,-15],[178,-17]]],[[[-180,-17],[-178,-17],[-178,-15],[-180,-15],[-180,-17]]]]}`
+)
+
+// pole is the area south of 70°S, closed along the antimeridian.
+const pole = `{"type":"Polygon","coordinates":[[[-180,-70],[0,-70],[180,-70],[180,-90],[-180,-90],[-180,-70]]]}`
+
+func TestDecode(t *testing.T) {
+	Convey("Given a polygon with a hole", t, func() {
+		m, err := Decode([]byte(square))
+		So(err, ShouldBeNil)
+
+		Convey("It should be decoded as a multipolygon of one polygon", func() {
+			So(len(m), ShouldEqual, 1)
+			So(len(m[0]), ShouldEqual, 2)
+			So(m[0][0][1], ShouldResemble, Point{Lon: 1, Lat: 0})
+		})
+
+		Convey("The coordinates in the hole should not be contained", func() {
+			So(m.Contains(0.2, 0.2), ShouldBeTrue)
+			So(m.Contains(0.5, 0.5), ShouldBeFalse)
+			So(m.Contains(1.5, 0.5), ShouldBeFalse)
+		})
+
+		Convey("The area of the hole should be subtracted", func() {
+			// a square degree at the equator is about 12364 km²
+			So(m.Area(), ShouldAlmostEqual, 12364*0.96, 20)
+		})
+
+		Convey("The bounding box should be the one of the outer ring", func() {
+			So(m.BBox(), ShouldResemble, BBox{MinLon: 0, MinLat: 0, MaxLon: 1, MaxLat: 1})
+		})
+
+		Convey("The centroid should be the center", func() {
+			c := m.Centroid()
+			So(c.Lon, ShouldAlmostEqual, 0.5, 1e-9)
+			So(c.Lat, ShouldAlmostEqual, 0.5, 1e-9)
+		})
+	})
+
+	Convey("Given a multipolygon", t, func() {
+		m, err := Decode([]byte(islands))
+		So(err, ShouldBeNil)
+
+		Convey("Every polygon should contain its coordinates", func() {
+			So(m.Contains(10, 10), ShouldBeTrue)
+			So(m.Contains(20.5, 19.5), ShouldBeTrue)
+			So(m.Contains(15, 15), ShouldBeFalse)
+		})
+
+		Convey("The bounding box should cover all polygons", func() {
+			b := m.BBox()
+			So(b, ShouldResemble, BBox{MinLon: 9, MinLat: 9, MaxLon: 21, MaxLat: 21})
+			So(b.Contains(15, 15), ShouldBeTrue)
+		})
+
+		Convey("The centroid should be between the polygons", func() {
+			c := m.Centroid()
+			So(c.Lon, ShouldAlmostEqual, 15, 1e-9)
+			So(c.Lat, ShouldAlmostEqual, 15, 1e-9)
+		})
+	})
+
+	Convey("Given an unsupported geometry", t, func() {
+		_, err := Decode([]byte(`{"type":"Point","coordinates":[1,2]}`))
+
+		Convey("The error should be returned", func() {
+			So(err, ShouldNotBeNil)
+		})
+	})
+	for name, data := range map[string]string{"polygon": fiji, "split polygon": fijiSplit} {
+		Convey(fmt.Sprintf("Given a %s across the antimeridian", name), t, func() {
+			m, err := Decode([]byte(data))
+			So(err, ShouldBeNil)
+
+			Convey("The coordinates on both sides should be contained", func() {
+				So(m.Contains(-16, 179), ShouldBeTrue)
+				So(m.Contains(-16, -179), ShouldBeTrue)
+				So(m.Contains(-16, 177), ShouldBeFalse)
+				So(m.Contains(-16, -177), ShouldBeFalse)
+				So(m.Contains(-16, 0), ShouldBeFalse)
+			})
+
+			Convey("The bounding box should cross the antimeridian", func() {
+				b := m.BBox()
+				So(b, ShouldResemble, BBox{MinLon: 178, MinLat: -17, MaxLon: -178, MaxLat: -15})
+				So(b.CrossesAntimeridian(), ShouldBeTrue)
+				So(b.Contains(-16, 179), ShouldBeTrue)
+				So(m[0].BBox().CrossesAntimeridian(), ShouldEqual, len(m) == 1)
+			})
+
+			Convey("The area should be the one of the square", func() {
+				// eight square degrees at 16°S
+				So(m.Area(), ShouldAlmostEqual, 8*12364*math.Cos(16*math.Pi/180), 100)
+			})
+
+			Convey("The centroid should be on the antimeridian", func() {
+				c := m.Centroid()
+				So(math.Abs(c.Lon), ShouldAlmostEqual, 180, 1e-9)
+				So(c.Lat, ShouldAlmostEqual, -16, 1e-9)
+			})
+		})
+	}
+
+	Convey("Given a polygon around a pole", t, func() {
+		m, err := Decode([]byte(pole))
+		So(err, ShouldBeNil)
+
+		Convey("The coordinates at every longitude should be contained", func() {
+			for _, lon := range []float64{-179, -90, 0, 90, 179} {
+				So(m.Contains(-80, lon), ShouldBeTrue)
+			}
+			So(m.Contains(-60, 0), ShouldBeFalse)
+		})
+	})
+}
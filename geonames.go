@@ -1,6 +1,7 @@
 package geonames
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/jszwec/csvutil"
@@ -8,10 +9,18 @@ import (
 	"github.com/mkrou/geonames/stream"
 	"io"
 	"net/http"
+	"strings"
 )
 
 const Url = "https://download.geonames.org/export/dump/"
 
+// ErrNotFound is returned by a parser when the requested dump file does not exist.
+var ErrNotFound = errors.New("does not exist")
+
+// ErrStop can be returned by a handler to stop the parsing without an error.
+// The file is closed and the Get* method returns nil.
+var ErrStop = errors.New("stop")
+
 //List of dump archives
 const (
 	Cities500                   models.GeoNameFile     = "cities500.zip"
@@ -21,6 +30,7 @@ const (
 	AllCountries                models.GeoNameFile     = "allCountries.zip"
 	NoCountry                   models.GeoNameFile     = "no-country.zip"
 	AlternateNames              models.AltNameFile     = "alternateNamesV2.zip"
+	AlternateNamesV1            models.AltNameFile     = "alternateNames.zip"
 	LangCodes                   models.DumpFile        = "iso-languagecodes.txt"
 	TimeZones                   models.DumpFile        = "timeZones.txt"
 	Countries                   models.DumpFile        = "countryInfo.txt"
@@ -33,6 +43,7 @@ const (
 	FeatureCodeSv               models.FeatureCodeFile = "featureCodes_sv.txt"
 	Hierarchy                   models.DumpFile        = "hierarchy.zip"
 	Shapes                      models.DumpFile        = "shapes_all_low.zip"
+	SimplifiedShapes            models.DumpFile        = "shapes_simplified_low.json.zip"
 	UserTags                    models.DumpFile        = "userTags.zip"
 	AdminDivisions              models.DumpFile        = "admin1CodesASCII.txt"
 	AdminSubDivisions           models.DumpFile        = "admin2Codes.txt"
@@ -43,120 +54,262 @@ const (
 	Modifications               models.DumpFile        = "modifications-%s.txt"
 )
 
-type Parser func(file string) (io.ReadCloser, error)
+// List of postal code archives, they are in the zip directory next to the dump directory.
+// The archive of a single country is returned by CountryPostalCodes.
+const (
+	PostalCodes       models.PostalCodeFile = "../zip/allCountries.zip"
+	PostalCodesGBFull models.PostalCodeFile = "../zip/GB_full.csv.zip"
+	PostalCodesNLFull models.PostalCodeFile = "../zip/NL_full.csv.zip"
+	PostalCodesCAFull models.PostalCodeFile = "../zip/CA_full.csv.zip"
+)
 
-func NewParser() Parser {
-	return Parser(func(file string) (io.ReadCloser, error) {
-		url := Url + file
-		resp, err := http.Get(url)
+// CountryPostalCodes returns the postal code archive of the country with the ISO-3166 2-letter code, e.g. "AD".
+func CountryPostalCodes(iso2 string) models.PostalCodeFile {
+	return models.PostalCodeFile("../zip/" + strings.ToUpper(iso2) + ".zip")
+}
+
+// Parser opens a dump file by its name relative to the dump directory.
+// The context must be used for any request made to obtain the file.
+type Parser func(ctx context.Context, file string) (io.ReadCloser, error)
+
+// NewParser returns a parser that downloads the dump files from Url
+// or from the base url given with WithBaseUrl.
+func NewParser(options ...Option) Parser {
+	c := newClient(options)
+
+	return Parser(func(ctx context.Context, file string) (io.ReadCloser, error) {
+		resp, err := c.get(ctx, file, nil)
 		if err != nil {
 			return nil, err
 		}
 
-		switch resp.StatusCode {
-		case 200:
-			return resp.Body, nil
-		case 404:
-			return nil, errors.New(fmt.Sprintf("Page %s does not exist", url))
-		default:
-			return nil, errors.New(fmt.Sprintf("Page %s returned unexpected code %d", url, resp.StatusCode))
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, statusError(resp.Request.URL.String(), resp.StatusCode)
 		}
+
+		return c.resumable(ctx, file, resp), nil
 	})
 }
 
-func (p Parser) handle(dump models.DumpFile, isHeadersEmpty bool, handler interface{}) error {
-	var err error
-	var headers = []string{}
-
-	model, err := getArgument(handler)
-	if err != nil {
-		return err
+func statusError(url string, code int) error {
+	if code == http.StatusNotFound {
+		return fmt.Errorf("Page %s %w", url, ErrNotFound)
 	}
+	return errors.New(fmt.Sprintf("Page %s returned unexpected code %d", url, code))
+}
 
-	if isHeadersEmpty {
-		headers, err = csvutil.Header(model, "csv")
-		if err != nil {
+// headerFiles are the dump files that start with a header line.
+var headerFiles = map[models.DumpFile]bool{
+	LangCodes: true,
+	TimeZones: true,
+	Shapes:    true,
+}
+
+// fileHeaders are the columns of the dump files without a header line
+// that have fewer columns than the fields of their model.
+var fileHeaders = map[models.DumpFile][]string{
+	models.DumpFile(AlternateNamesV1): {"alternateNameId", "geonameid", "isolanguage", "alternate name", "isPreferredName", "isShortName", "isColloquial", "isHistoric"},
+}
+
+// Stream decodes every record of the dump file into a new T and passes it to handler.
+// T must be a model with csv tags, e.g. models.Geoname for the GeoNameFile archives.
+// Files without a header line are matched by the order of the tagged fields of T,
+// so T has to declare every column of such a file.
+//
+// Options such as stream.Workers configure the decoding.
+func Stream[T any](p Parser, dump models.DumpFile, handler func(*T) error, options ...stream.Option) error {
+	return StreamContext(context.Background(), p, dump, handler, options...)
+}
+
+// StreamContext is like Stream but stops the download and the parsing when ctx is done.
+// The file opened by the parser is always closed before StreamContext returns,
+// also when the handler fails or panics.
+func StreamContext[T any](ctx context.Context, p Parser, dump models.DumpFile, handler func(*T) error, options ...stream.Option) error {
+	headers := fileHeaders[dump]
+	if headers == nil && !headerFiles[dump] {
+		var err error
+		if headers, err = csvutil.Header(new(T), "csv"); err != nil {
 			return err
 		}
 	}
 
-	r, err := p(dump.String())
+	options = append(options[:len(options):len(options)], stream.Source(dump.String()))
+	return open(ctx, p, dump, func(r io.Reader) error {
+		if dump.IsArchive() {
+			return stream.Archive(ctx, r, dump.TextFilename(), headers, handler, options...)
+		}
+		return stream.File(ctx, r, headers, handler, options...)
+	})
+}
+
+// open passes the dump file opened by p to parse and closes it afterwards.
+// ErrStop returned by parse ends the parsing without an error.
+func open(ctx context.Context, p Parser, dump models.DumpFile, parse func(r io.Reader) error) (err error) {
+	r, err := p(ctx, dump.String())
 	if err != nil {
+		if r != nil {
+			r.Close()
+		}
 		return err
 	}
-	f := func(parse func(v interface{}) error) error {
-		return fillArgument(handler, parse)
-	}
+	defer func() {
+		if cerr := r.Close(); err == nil {
+			err = cerr
+		}
+	}()
 
-	if dump.IsArchive() {
-		err = stream.StreamArchive(r, dump.TextFilename(), f, headers)
-	} else {
-		err = stream.StreamFile(r, f, headers)
+	err = parse(r)
+	if errors.Is(err, ErrStop) {
+		return nil
 	}
-
 	return err
 }
 
 func (p Parser) GetGeonames(archive models.GeoNameFile, handler func(*models.Geoname) error) error {
-	return p.handle(models.DumpFile(archive), true, handler)
+	return p.GetGeonamesContext(context.Background(), archive, handler)
+}
+
+func (p Parser) GetGeonamesContext(ctx context.Context, archive models.GeoNameFile, handler func(*models.Geoname) error) error {
+	return StreamContext(ctx, p, models.DumpFile(archive), handler)
 }
 
 func (p Parser) GetAlternateNames(archive models.AltNameFile, handler func(*models.AlternateName) error) error {
-	return p.handle(models.DumpFile(archive), true, handler)
+	return p.GetAlternateNamesContext(context.Background(), archive, handler)
+}
+
+func (p Parser) GetAlternateNamesContext(ctx context.Context, archive models.AltNameFile, handler func(*models.AlternateName) error) error {
+	return StreamContext(ctx, p, models.DumpFile(archive), handler)
 }
 
 func (p Parser) GetLanguages(handler func(*models.Language) error) error {
-	return p.handle(LangCodes, false, handler)
+	return p.GetLanguagesContext(context.Background(), handler)
+}
+
+func (p Parser) GetLanguagesContext(ctx context.Context, handler func(*models.Language) error) error {
+	return StreamContext(ctx, p, LangCodes, handler)
 }
 
 func (p Parser) GetTimeZones(handler func(*models.TimeZone) error) error {
-	return p.handle(TimeZones, false, handler)
+	return p.GetTimeZonesContext(context.Background(), handler)
+}
+
+func (p Parser) GetTimeZonesContext(ctx context.Context, handler func(*models.TimeZone) error) error {
+	return StreamContext(ctx, p, TimeZones, handler)
 }
 
 func (p Parser) GetCountries(handler func(*models.Country) error) error {
-	return p.handle(Countries, true, handler)
+	return p.GetCountriesContext(context.Background(), handler)
+}
+
+func (p Parser) GetCountriesContext(ctx context.Context, handler func(*models.Country) error) error {
+	return StreamContext(ctx, p, Countries, handler)
 }
 
 func (p Parser) GetFeatureCodes(file models.FeatureCodeFile, handler func(*models.FeatureCode) error) error {
-	return p.handle(models.DumpFile(file), true, handler)
+	return p.GetFeatureCodesContext(context.Background(), file, handler)
+}
+
+func (p Parser) GetFeatureCodesContext(ctx context.Context, file models.FeatureCodeFile, handler func(*models.FeatureCode) error) error {
+	return StreamContext(ctx, p, models.DumpFile(file), handler)
 }
 
 func (p Parser) GetHierarchy(handler func(*models.Hierarchy) error) error {
-	return p.handle(Hierarchy, true, handler)
+	return p.GetHierarchyContext(context.Background(), handler)
+}
+
+func (p Parser) GetHierarchyContext(ctx context.Context, handler func(*models.Hierarchy) error) error {
+	return StreamContext(ctx, p, Hierarchy, handler)
 }
 
 func (p Parser) GetShapes(handler func(*models.Shape) error) error {
-	return p.handle(Shapes, false, handler)
+	return p.GetShapesContext(context.Background(), handler)
+}
+
+func (p Parser) GetShapesContext(ctx context.Context, handler func(*models.Shape) error) error {
+	return StreamContext(ctx, p, Shapes, handler)
+}
+
+func (p Parser) GetSimplifiedShapes(handler func(*models.SimplifiedShape) error) error {
+	return p.GetSimplifiedShapesContext(context.Background(), handler)
+}
+
+// GetSimplifiedShapesContext decodes the features of the GeoJSON one by one,
+// without reading the whole document into memory.
+func (p Parser) GetSimplifiedShapesContext(ctx context.Context, handler func(*models.SimplifiedShape) error) error {
+	return open(ctx, p, SimplifiedShapes, func(r io.Reader) error {
+		return stream.ArchiveFeatures(ctx, r, SimplifiedShapes.TextFilename(), handler)
+	})
+}
+
+func (p Parser) GetPostalCodes(archive models.PostalCodeFile, handler func(*models.PostalCode) error) error {
+	return p.GetPostalCodesContext(context.Background(), archive, handler)
+}
+
+func (p Parser) GetPostalCodesContext(ctx context.Context, archive models.PostalCodeFile, handler func(*models.PostalCode) error) error {
+	return StreamContext(ctx, p, models.DumpFile(archive), handler)
 }
 
 func (p Parser) GetUserTags(handler func(*models.UserTag) error) error {
-	return p.handle(UserTags, true, handler)
+	return p.GetUserTagsContext(context.Background(), handler)
+}
+
+func (p Parser) GetUserTagsContext(ctx context.Context, handler func(*models.UserTag) error) error {
+	return StreamContext(ctx, p, UserTags, handler)
 }
 
 func (p Parser) GetAdminDivisions(handler func(*models.AdminDivision) error) error {
-	return p.handle(AdminDivisions, true, handler)
+	return p.GetAdminDivisionsContext(context.Background(), handler)
+}
+
+func (p Parser) GetAdminDivisionsContext(ctx context.Context, handler func(*models.AdminDivision) error) error {
+	return StreamContext(ctx, p, AdminDivisions, handler)
 }
 
 func (p Parser) GetAdminSubdivisions(handler func(*models.AdminSubdivision) error) error {
-	return p.handle(AdminSubDivisions, true, handler)
+	return p.GetAdminSubdivisionsContext(context.Background(), handler)
+}
+
+func (p Parser) GetAdminSubdivisionsContext(ctx context.Context, handler func(*models.AdminSubdivision) error) error {
+	return StreamContext(ctx, p, AdminSubDivisions, handler)
 }
 
 func (p Parser) GetAdminCodes5(handler func(*models.AdminCode5) error) error {
-	return p.handle(AdminCode5, true, handler)
+	return p.GetAdminCodes5Context(context.Background(), handler)
+}
+
+func (p Parser) GetAdminCodes5Context(ctx context.Context, handler func(*models.AdminCode5) error) error {
+	return StreamContext(ctx, p, AdminCode5, handler)
 }
 
 func (p Parser) GetAlternateNameDeletes(handler func(*models.AlternateNameDelete) error) error {
-	return p.handle(AlternateNamesDeletes.WithLastDate(), true, handler)
+	return p.GetAlternateNameDeletesContext(context.Background(), handler)
+}
+
+func (p Parser) GetAlternateNameDeletesContext(ctx context.Context, handler func(*models.AlternateNameDelete) error) error {
+	return StreamContext(ctx, p, AlternateNamesDeletes.WithLastDate(), handler)
 }
 
 func (p Parser) GetAlternateNameModifications(handler func(*models.AlternateNameModification) error) error {
-	return p.handle(AlternateNamesModifications.WithLastDate(), true, handler)
+	return p.GetAlternateNameModificationsContext(context.Background(), handler)
+}
+
+func (p Parser) GetAlternateNameModificationsContext(ctx context.Context, handler func(*models.AlternateNameModification) error) error {
+	return StreamContext(ctx, p, AlternateNamesModifications.WithLastDate(), handler)
 }
 
 func (p Parser) GetDeletes(handler func(*models.GeonameDelete) error) error {
-	return p.handle(Deletes.WithLastDate(), true, handler)
+	return p.GetDeletesContext(context.Background(), handler)
+}
+
+func (p Parser) GetDeletesContext(ctx context.Context, handler func(*models.GeonameDelete) error) error {
+	return StreamContext(ctx, p, Deletes.WithLastDate(), handler)
 }
 
 func (p Parser) GetModifications(handler func(*models.Geoname) error) error {
-	return p.handle(Modifications.WithLastDate(), true, handler)
+	return p.GetModificationsContext(context.Background(), handler)
+}
+
+func (p Parser) GetModificationsContext(ctx context.Context, handler func(*models.Geoname) error) error {
+	return StreamContext(ctx, p, Modifications.WithLastDate(), handler)
 }
@@ -1,12 +1,16 @@
 module github.com/mkrou/geonames
 
+go 1.23
+
 require (
 	github.com/asaskevich/govalidator v0.0.0-20180720115003-f9ffefc3facf
 	github.com/gernest/wow v0.1.1-0.20190121092615-f84922eda44e
 	github.com/gopherjs/gopherjs v0.0.0-20181103185306-d547d1d9531e // indirect
 	github.com/jszwec/csvutil v1.2.1
-	github.com/jtolds/gls v4.2.1+incompatible // indirect
+	github.com/jtolds/gls v4.20.0+incompatible // indirect
 	github.com/krolaw/zipstream v0.0.0-20180621105154-0a2661891f94
 	github.com/smartystreets/assertions v0.0.0-20190116191733-b6c0e53d7304 // indirect
 	github.com/smartystreets/goconvey v0.0.0-20181108003508-044398e4856c
+	golang.org/x/crypto v0.0.0-20190103213133-ff983b9c42bc // indirect
+	golang.org/x/sys v0.0.0-20190116161447-11f53e031339 // indirect
 )
@@ -0,0 +1,273 @@
+// Package hierarchy navigates the parents and the children of the geonames
+// given by hierarchy.txt and the administrative codes of the geonames.
+package hierarchy
+
+import (
+	"github.com/mkrou/geonames/featureclass"
+	"github.com/mkrou/geonames/internal/intern"
+	"github.com/mkrou/geonames/models"
+	"strings"
+)
+
+// Builder collects the edges of a graph. Its Add and AddGeoname methods can be passed
+// to the Get* methods of a parser:
+//
+//	b := hierarchy.NewBuilder("ADM")
+//	err := p.GetHierarchy(b.Add)
+//	...
+//	err = p.GetGeonames(geonames.AllCountries, b.AddGeoname)
+//	...
+//	g := b.Build()
+type Builder struct {
+	types    map[string]bool
+	edges    map[[2]int]bool
+	parents  map[int][]int
+	children map[int][]int
+	geonames []admin
+	codes    intern.Strings // one copy of every administrative code
+}
+
+// admin is what is needed of a geoname to derive its parent.
+type admin struct {
+	id    int
+	level int // level of the administrative division the geoname is, -1 if it is none
+	codes [5]string
+}
+
+// NewBuilder returns a builder that keeps the edges of the types only, e.g. "ADM", or all of them without types.
+func NewBuilder(types ...string) *Builder {
+	b := &Builder{
+		edges:    map[[2]int]bool{},
+		parents:  map[int][]int{},
+		children: map[int][]int{},
+		codes:    intern.Strings{},
+	}
+	if len(types) > 0 {
+		b.types = map[string]bool{}
+		for _, t := range types {
+			b.types[t] = true
+		}
+	}
+	return b
+}
+
+// Add adds the edge of hierarchy.txt unless its type is filtered out. It always returns nil.
+func (b *Builder) Add(h *models.Hierarchy) error {
+	if b.types == nil || b.types[h.Type] {
+		b.add(h.Parent, h.Child)
+	}
+	return nil
+}
+
+// countryCodes are the feature codes of the geonames that are the division of level 0 of their country code.
+// The historical countries (PCLH), the sections of countries (PCLIX) and the semi-independent
+// entities (PCLS) often share the country code of another geoname, so they are not.
+var countryCodes = map[string]bool{"PCL": true, "PCLI": true, "PCLD": true, "PCLF": true}
+
+// AddGeoname keeps the administrative codes of the geoname. When the graph is built,
+// a geoname without a parent in hierarchy.txt gets the administrative division of its codes as the parent,
+// e.g. the ADM1 of its admin1 code, or the country. It always returns nil.
+func (b *Builder) AddGeoname(g *models.Geoname) error {
+	a := admin{id: g.Id, level: -1}
+	for i, code := range []string{g.CountryCode, g.Admin1Code, g.Admin2Code, g.Admin3Code, g.Admin4Code} {
+		a.codes[i] = b.codes.Intern(code)
+	}
+	if g.FeatureClass() == featureclass.Administrative {
+		switch {
+		case countryCodes[g.Code]:
+			a.level = 0
+		case len(g.Code) == 4 && strings.HasPrefix(g.Code, "ADM") && g.Code[3] >= '1' && g.Code[3] <= '4':
+			a.level = int(g.Code[3] - '0')
+		}
+	}
+	b.geonames = append(b.geonames, a)
+	return nil
+}
+
+func (b *Builder) add(parent, child int) {
+	edge := [2]int{parent, child}
+	if b.edges[edge] {
+		return
+	}
+	b.edges[edge] = true
+	b.parents[child] = append(b.parents[child], parent)
+	b.children[parent] = append(b.children[parent], child)
+}
+
+// Build returns the graph of the added edges. The builder must not be used afterwards.
+func (b *Builder) Build() *Graph {
+	b.derive()
+	g := &Graph{parents: b.parents, children: b.children}
+	*b = Builder{}
+	return g
+}
+
+// derive adds the parents of the geonames without one from their administrative codes.
+// A division that is already a descendant of the geoname in hierarchy.txt is skipped,
+// so a derived edge never closes a cycle, and the next division up is tried instead.
+func (b *Builder) derive() {
+	divisions := map[[5]string]int{}
+	for _, a := range b.geonames {
+		if a.level >= 0 {
+			divisions[a.key(a.level)] = a.id
+		}
+	}
+
+	for _, a := range b.geonames {
+		if len(b.parents[a.id]) > 0 {
+			continue
+		}
+
+		level := a.level - 1
+		if a.level < 0 {
+			level = a.deepest()
+		}
+		for ; level >= 0; level-- {
+			if parent, ok := divisions[a.key(level)]; ok && parent != a.id && !b.isAncestor(a.id, parent) {
+				b.add(parent, a.id)
+				break
+			}
+		}
+	}
+}
+
+// isAncestor reports whether id is an ancestor of the geoname of.
+func (b *Builder) isAncestor(id, of int) bool {
+	if len(b.children[id]) == 0 {
+		return false
+	}
+
+	seen := map[int]bool{}
+	stack := []int{of}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, parent := range b.parents[n] {
+			if parent == id {
+				return true
+			}
+			if !seen[parent] {
+				seen[parent] = true
+				stack = append(stack, parent)
+			}
+		}
+	}
+	return false
+}
+
+// key returns the codes of the administrative division of the level the geoname is in.
+func (a admin) key(level int) [5]string {
+	var k [5]string
+	copy(k[:level+1], a.codes[:level+1])
+	return k
+}
+
+// deepest returns the level of the deepest administrative division the geoname is in.
+func (a admin) deepest() int {
+	level := 0
+	for i := 1; i < len(a.codes) && a.codes[i] != "" && a.codes[i] != "00"; i++ {
+		level = i
+	}
+	return level
+}
+
+// Graph is an immutable graph of the geonames. It is safe for concurrent use.
+// The returned lists must not be modified.
+type Graph struct {
+	parents  map[int][]int
+	children map[int][]int
+}
+
+// Parents returns the direct parents of the geoname.
+func (g *Graph) Parents(id int) []int {
+	return g.parents[id]
+}
+
+// Children returns the direct children of the geoname.
+func (g *Graph) Children(id int) []int {
+	return g.children[id]
+}
+
+// Ancestors returns the parents of the geoname, their parents and so on, the nearest first.
+// Every ancestor is returned once, also when the graph has cycles.
+func (g *Graph) Ancestors(id int) []int {
+	return walk(g.parents, id, 0)
+}
+
+// Descendants returns the children of the geoname down to depth levels, the nearest first.
+// A depth of 0 or less returns all the descendants.
+func (g *Graph) Descendants(id int, depth int) []int {
+	return walk(g.children, id, depth)
+}
+
+// walk returns the nodes reachable from id in breadth-first order.
+func walk(edges map[int][]int, id int, depth int) []int {
+	var found []int
+	seen := map[int]bool{id: true}
+	level := []int{id}
+	for d := 1; len(level) > 0 && (depth <= 0 || d <= depth); d++ {
+		var next []int
+		for _, n := range level {
+			for _, m := range edges[n] {
+				if !seen[m] {
+					seen[m] = true
+					found = append(found, m)
+					next = append(next, m)
+				}
+			}
+		}
+		level = next
+	}
+	return found
+}
+
+// FindCycle returns the ids of a cycle of the graph with the first id repeated at the end,
+// or nil if the graph has no cycles.
+func (g *Graph) FindCycle() []int {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[int]int{}
+
+	type frame struct {
+		id   int
+		next int // index of the next child to visit
+	}
+	for root := range g.children {
+		if state[root] != unvisited {
+			continue
+		}
+
+		stack := []frame{{id: root}}
+		state[root] = visiting
+		for len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			children := g.children[top.id]
+			if top.next == len(children) {
+				state[top.id] = visited
+				stack = stack[:len(stack)-1]
+				continue
+			}
+
+			child := children[top.next]
+			top.next++
+			switch state[child] {
+			case unvisited:
+				state[child] = visiting
+				stack = append(stack, frame{id: child})
+			case visiting:
+				var cycle []int
+				for i := len(stack) - 1; i >= 0; i-- {
+					cycle = append([]int{stack[i].id}, cycle...)
+					if stack[i].id == child {
+						break
+					}
+				}
+				return append(cycle, child)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package hierarchy
+
+import (
+	"github.com/mkrou/geonames/models"
+	. "github.com/smartystreets/goconvey/convey"
+	"sort"
+	"testing"
+)
+
+const (
+	earth    = 6295630
+	europe   = 6255148
+	andorra  = 3041565
+	canillo  = 3041203
+	elTarter = 3039154
+	soldeu   = 3039110
+)
+
+func sorted(ids []int) []int {
+	ids = append([]int(nil), ids...)
+	sort.Ints(ids)
+	return ids
+}
+
+func TestGraph(t *testing.T) {
+	Convey("Given the edges of hierarchy.txt", t, func() {
+		b := NewBuilder("ADM")
+		for _, h := range []models.Hierarchy{
+			{Parent: earth, Child: europe},
+			{Parent: europe, Child: andorra},
+			{Parent: andorra, Child: canillo, Type: "ADM"},
+			{Parent: canillo, Child: elTarter, Type: "ADM"},
+			{Parent: canillo, Child: elTarter, Type: "ADM"},
+			{Parent: 1, Child: canillo, Type: "user"},
+		} {
+			So(b.Add(&h), ShouldBeNil)
+		}
+
+		Convey("When only the ADM edges are kept", func() {
+			g := b.Build()
+
+			Convey("The ancestors should be found, the nearest first", func() {
+				So(g.Ancestors(elTarter), ShouldResemble, []int{canillo, andorra})
+				So(g.Parents(canillo), ShouldResemble, []int{andorra})
+			})
+
+			Convey("The duplicate edges should be kept once", func() {
+				So(g.Children(canillo), ShouldResemble, []int{elTarter})
+			})
+
+			Convey("The descendants should be limited by the depth", func() {
+				So(g.Descendants(andorra, 1), ShouldResemble, []int{canillo})
+				So(g.Descendants(andorra, 0), ShouldResemble, []int{canillo, elTarter})
+			})
+
+			Convey("There should be no cycle", func() {
+				So(g.FindCycle(), ShouldBeNil)
+			})
+		})
+	})
+
+	Convey("Given edges of all types", t, func() {
+		b := NewBuilder()
+		b.Add(&models.Hierarchy{Parent: earth, Child: europe})
+		b.Add(&models.Hierarchy{Parent: europe, Child: andorra})
+		g := b.Build()
+
+		So(g.Ancestors(andorra), ShouldResemble, []int{europe, earth})
+	})
+
+	Convey("Given edges with a cycle", t, func() {
+		b := NewBuilder()
+		b.Add(&models.Hierarchy{Parent: earth, Child: 1})
+		b.Add(&models.Hierarchy{Parent: 1, Child: 2})
+		b.Add(&models.Hierarchy{Parent: 2, Child: 3})
+		b.Add(&models.Hierarchy{Parent: 3, Child: 1})
+		g := b.Build()
+
+		Convey("The cycle should be found", func() {
+			cycle := g.FindCycle()
+			So(len(cycle), ShouldEqual, 4)
+			So(cycle[0], ShouldEqual, cycle[3])
+			So(sorted(cycle[:3]), ShouldResemble, []int{1, 2, 3})
+		})
+
+		Convey("The navigation should end", func() {
+			So(sorted(g.Ancestors(3)), ShouldResemble, []int{1, 2, earth})
+			So(sorted(g.Descendants(earth, 0)), ShouldResemble, []int{1, 2, 3})
+		})
+	})
+
+	Convey("Given a gap in hierarchy.txt", t, func() {
+		b := NewBuilder("ADM")
+		b.Add(&models.Hierarchy{Parent: andorra, Child: canillo, Type: "ADM"})
+		for _, g := range []models.Geoname{
+			{Id: andorra, Class: "A", Code: "PCLI", CountryCode: "AD", Admin1Code: "00"},
+			{Id: canillo, Class: "A", Code: "ADM1", CountryCode: "AD", Admin1Code: "02"},
+			{Id: elTarter, Class: "P", Code: "PPL", CountryCode: "AD", Admin1Code: "02"},
+			{Id: soldeu, Class: "P", Code: "PPL", CountryCode: "AD", Admin1Code: "09"},
+			{Id: 1, Class: "A", Code: "PCLH", CountryCode: "AD"},
+			{Id: 2, Class: "A", Code: "PCLIX", CountryCode: "AD"},
+		} {
+			So(b.AddGeoname(&g), ShouldBeNil)
+		}
+		g := b.Build()
+
+		Convey("The parents should be derived from the admin codes", func() {
+			So(g.Ancestors(elTarter), ShouldResemble, []int{canillo, andorra})
+		})
+
+		Convey("A missing division should fall back to the country", func() {
+			So(g.Parents(soldeu), ShouldResemble, []int{andorra})
+		})
+
+		Convey("The country should have no parent", func() {
+			So(g.Parents(andorra), ShouldBeEmpty)
+		})
+
+		Convey("A historical country or a section should not be the country", func() {
+			So(g.Parents(1), ShouldResemble, []int{andorra})
+			So(g.Parents(2), ShouldResemble, []int{andorra})
+		})
+	})
+
+	Convey("Given a division that is a child of a place in hierarchy.txt", t, func() {
+		b := NewBuilder()
+		b.Add(&models.Hierarchy{Parent: elTarter, Child: canillo})
+		for _, g := range []models.Geoname{
+			{Id: andorra, Class: "A", Code: "PCLI", CountryCode: "AD"},
+			{Id: canillo, Class: "A", Code: "ADM1", CountryCode: "AD", Admin1Code: "02"},
+			{Id: elTarter, Class: "P", Code: "PPL", CountryCode: "AD", Admin1Code: "02"},
+		} {
+			b.AddGeoname(&g)
+		}
+		g := b.Build()
+
+		Convey("The division should not be derived as the parent of the place", func() {
+			So(g.FindCycle(), ShouldBeNil)
+			So(g.Parents(elTarter), ShouldResemble, []int{andorra})
+			So(g.Ancestors(canillo), ShouldResemble, []int{elTarter, andorra})
+		})
+	})
+}
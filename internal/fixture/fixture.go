@@ -0,0 +1,54 @@
+// Package fixture builds the dump files used by the tests of the packages of the module.
+package fixture
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Geonames returns n geoname rows with ids from 1 to n.
+func Geonames(n int) string {
+	buf := &bytes.Buffer{}
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(buf, "%d\tPlace %d\tPlace %d\tPlace,Plaats,Ort %d\t%.5f\t%.5f\tP\tPPL\tAD\t\t%02d\t\t\t\t%d\t\t%d\tEurope/Andorra\t2012-11-03\n",
+			i, i, i, i, float64(i%180)-89.5, float64(i%360)-179.5, i%8, i*7%10000, i%3000)
+	}
+	return buf.String()
+}
+
+// Zip returns a zip archive containing a single entry.
+func Zip(name, content string) []byte {
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	f, err := w.Create(name)
+	if err != nil {
+		panic(err)
+	}
+	if _, err := f.Write([]byte(content)); err != nil {
+		panic(err)
+	}
+	if err := w.Close(); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
+
+// Dir creates a dump directory with the given files, whose names may contain
+// slashes for subdirectories. The directory is removed when the test ends.
+func Dir(t testing.TB, files map[string][]byte) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
@@ -0,0 +1,21 @@
+// Package intern keeps one copy of the strings that repeat across the records of a dump.
+package intern
+
+import "strings"
+
+// Strings maps every kept string to itself. The decoded strings are slices of the whole line,
+// so the kept ones are cloned to let the line be collected.
+type Strings map[string]string
+
+// Intern returns the kept copy of s, keeping a clone of s if there is none yet.
+func (in Strings) Intern(s string) string {
+	if s == "" {
+		return ""
+	}
+	if v, ok := in[s]; ok {
+		return v
+	}
+	s = strings.Clone(s)
+	in[s] = s
+	return s
+}
@@ -0,0 +1,192 @@
+package geonames
+
+import (
+	"context"
+	"github.com/mkrou/geonames/models"
+	"github.com/mkrou/geonames/stream"
+	"iter"
+	"sync"
+)
+
+// Seq returns the records of the dump file as a sequence for a range loop.
+// When the parsing fails, the sequence yields the error with a nil value and ends.
+// Leaving the loop early stops the parsing and closes the file. A range loop can not be
+// given an error of closing the file then, an Iterator returns it from Close.
+func Seq[T any](ctx context.Context, p Parser, dump models.DumpFile, options ...stream.Option) iter.Seq2[*T, error] {
+	return seq(func(handler func(*T) error) error {
+		return StreamContext(ctx, p, dump, handler, options...)
+	})
+}
+
+// seq turns a parsing function into a sequence with the semantics of Seq.
+func seq[T any](parse func(handler func(*T) error) error) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		var stoppedAt *T
+		err := parse(func(v *T) error {
+			if !yield(v, nil) {
+				stoppedAt = v
+				return ErrStop
+			}
+			return nil
+		})
+
+		switch {
+		case stoppedAt != nil:
+			// yield must not be called again once it returned false
+			if report, ok := stopErrors.LoadAndDelete(stoppedAt); ok && err != nil {
+				report.(func(error))(err)
+			}
+		case err != nil:
+			yield(nil, err)
+		}
+	}
+}
+
+// stopErrors maps the record an Iterator was closed at to the function that records
+// the error of stopping the sequence, which the sequence can not yield anymore.
+var stopErrors sync.Map
+
+// Iterator reads the records of a sequence one by one:
+//
+//	it := geonames.NewIterator(p.GeonamesSeq(ctx, geonames.Cities500))
+//	defer it.Close()
+//	for it.Next() {
+//		fmt.Println(it.Value().Name)
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type Iterator[T any] struct {
+	next  func() (*T, error, bool)
+	stop  func()
+	value *T
+	err   error
+	last  *T // record the sequence is waiting at, it is stopped there by Close
+}
+
+// NewIterator returns an iterator over the sequence. It must be closed
+// if it is not read till the end.
+func NewIterator[T any](seq iter.Seq2[*T, error]) *Iterator[T] {
+	next, stop := iter.Pull2(seq)
+	return &Iterator[T]{next: next, stop: stop}
+}
+
+// Next advances the iterator to the next record and reports whether there is one.
+func (it *Iterator[T]) Next() bool {
+	it.value = nil
+	v, err, ok := it.next()
+	if !ok {
+		it.last = nil
+		return false
+	}
+	if err != nil {
+		it.err, it.last = err, nil
+		it.stop()
+		return false
+	}
+
+	it.value, it.last = v, v
+	return true
+}
+
+// Value returns the current record.
+func (it *Iterator[T]) Value() *T {
+	return it.value
+}
+
+// Err returns the error that ended the iteration or that closing the file returned, if any.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// Close stops the parsing and closes the file. It returns the error of closing the file
+// if the iteration is stopped before the end, otherwise the one of Err.
+// It is safe to call Close more than once.
+func (it *Iterator[T]) Close() error {
+	if it.last != nil {
+		last := it.last
+		it.last = nil
+		stopErrors.Store(last, func(err error) {
+			it.err = err
+		})
+		it.stop()
+		stopErrors.Delete(last)
+	}
+	it.stop()
+	return it.err
+}
+
+func (p Parser) GeonamesSeq(ctx context.Context, archive models.GeoNameFile) iter.Seq2[*models.Geoname, error] {
+	return Seq[models.Geoname](ctx, p, models.DumpFile(archive))
+}
+
+func (p Parser) AlternateNamesSeq(ctx context.Context, archive models.AltNameFile) iter.Seq2[*models.AlternateName, error] {
+	return Seq[models.AlternateName](ctx, p, models.DumpFile(archive))
+}
+
+func (p Parser) LanguagesSeq(ctx context.Context) iter.Seq2[*models.Language, error] {
+	return Seq[models.Language](ctx, p, LangCodes)
+}
+
+func (p Parser) TimeZonesSeq(ctx context.Context) iter.Seq2[*models.TimeZone, error] {
+	return Seq[models.TimeZone](ctx, p, TimeZones)
+}
+
+func (p Parser) CountriesSeq(ctx context.Context) iter.Seq2[*models.Country, error] {
+	return Seq[models.Country](ctx, p, Countries)
+}
+
+func (p Parser) FeatureCodesSeq(ctx context.Context, file models.FeatureCodeFile) iter.Seq2[*models.FeatureCode, error] {
+	return Seq[models.FeatureCode](ctx, p, models.DumpFile(file))
+}
+
+func (p Parser) HierarchySeq(ctx context.Context) iter.Seq2[*models.Hierarchy, error] {
+	return Seq[models.Hierarchy](ctx, p, Hierarchy)
+}
+
+func (p Parser) ShapesSeq(ctx context.Context) iter.Seq2[*models.Shape, error] {
+	return Seq[models.Shape](ctx, p, Shapes)
+}
+
+// SimplifiedShapesSeq returns the features of the simplified shapes one by one, see GetSimplifiedShapesContext.
+func (p Parser) SimplifiedShapesSeq(ctx context.Context) iter.Seq2[*models.SimplifiedShape, error] {
+	return seq(func(handler func(*models.SimplifiedShape) error) error {
+		return p.GetSimplifiedShapesContext(ctx, handler)
+	})
+}
+
+func (p Parser) PostalCodesSeq(ctx context.Context, archive models.PostalCodeFile) iter.Seq2[*models.PostalCode, error] {
+	return Seq[models.PostalCode](ctx, p, models.DumpFile(archive))
+}
+
+func (p Parser) UserTagsSeq(ctx context.Context) iter.Seq2[*models.UserTag, error] {
+	return Seq[models.UserTag](ctx, p, UserTags)
+}
+
+func (p Parser) AdminDivisionsSeq(ctx context.Context) iter.Seq2[*models.AdminDivision, error] {
+	return Seq[models.AdminDivision](ctx, p, AdminDivisions)
+}
+
+func (p Parser) AdminSubdivisionsSeq(ctx context.Context) iter.Seq2[*models.AdminSubdivision, error] {
+	return Seq[models.AdminSubdivision](ctx, p, AdminSubDivisions)
+}
+
+func (p Parser) AdminCodes5Seq(ctx context.Context) iter.Seq2[*models.AdminCode5, error] {
+	return Seq[models.AdminCode5](ctx, p, AdminCode5)
+}
+
+func (p Parser) AlternateNameDeletesSeq(ctx context.Context) iter.Seq2[*models.AlternateNameDelete, error] {
+	return Seq[models.AlternateNameDelete](ctx, p, AlternateNamesDeletes.WithLastDate())
+}
+
+func (p Parser) AlternateNameModificationsSeq(ctx context.Context) iter.Seq2[*models.AlternateNameModification, error] {
+	return Seq[models.AlternateNameModification](ctx, p, AlternateNamesModifications.WithLastDate())
+}
+
+func (p Parser) DeletesSeq(ctx context.Context) iter.Seq2[*models.GeonameDelete, error] {
+	return Seq[models.GeonameDelete](ctx, p, Deletes.WithLastDate())
+}
+
+func (p Parser) ModificationsSeq(ctx context.Context) iter.Seq2[*models.Geoname, error] {
+	return Seq[models.Geoname](ctx, p, Modifications.WithLastDate())
+}
@@ -0,0 +1,84 @@
+package geonames
+
+import (
+	"context"
+	"errors"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestIterators(t *testing.T) {
+	Convey("Given a parser over a local mirror", t, func() {
+		dir := mirror(t, map[string][]byte{
+			"cities500.zip":         zipped("cities500.txt", geonameRows),
+			"alternatenames/AD.zip": zipped("AD.txt", alternateNameRows),
+		})
+		p := NewDirParser(dir)
+		ctx := context.Background()
+
+		Convey("When a sequence is ranged over", func() {
+			var names []string
+			for x, err := range p.GeonamesSeq(ctx, Cities500) {
+				So(err, ShouldBeNil)
+				names = append(names, x.Name)
+			}
+
+			So(names, ShouldResemble, []string{"El Tarter", "Sant Julià de Lòria", "Pas de la Casa"})
+		})
+
+		Convey("When the loop is left early", func() {
+			count := 0
+			for _, err := range p.GeonamesSeq(ctx, Cities500) {
+				So(err, ShouldBeNil)
+				count++
+				if count == 2 {
+					break
+				}
+			}
+
+			So(count, ShouldEqual, 2)
+		})
+
+		Convey("When a missing file is ranged over", func() {
+			var errs []error
+			for x, err := range p.GeonamesSeq(ctx, Cities1000) {
+				So(x, ShouldBeNil)
+				errs = append(errs, err)
+			}
+
+			So(len(errs), ShouldEqual, 1)
+			So(errors.Is(errs[0], ErrNotFound), ShouldBeTrue)
+		})
+
+		Convey("When two iterators are merged", func() {
+			geonames := NewIterator(p.GeonamesSeq(ctx, Cities500))
+			defer geonames.Close()
+			names := NewIterator(p.AlternateNamesSeq(ctx, "alternatenames/AD.zip"))
+			defer names.Close()
+
+			found := map[string]int{}
+			for names.Next() {
+				for geonames.Value() == nil || geonames.Value().Id < names.Value().GeonameId {
+					if !geonames.Next() {
+						break
+					}
+				}
+				if g := geonames.Value(); g != nil && g.Id == names.Value().GeonameId {
+					found[names.Value().Name] = g.Id
+				}
+			}
+
+			So(names.Err(), ShouldBeNil)
+			So(geonames.Err(), ShouldBeNil)
+			So(found, ShouldResemble, map[string]int{"El Tarter": 3039154, "Эль-Тартер": 3039154})
+		})
+
+		Convey("When an iterator over a missing file is read", func() {
+			it := NewIterator(p.GeonamesSeq(ctx, Cities1000))
+			defer it.Close()
+
+			So(it.Next(), ShouldBeFalse)
+			So(errors.Is(it.Err(), ErrNotFound), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,161 @@
+package geonames
+
+import (
+	"context"
+	"errors"
+	"github.com/mkrou/geonames/models"
+	. "github.com/smartystreets/goconvey/convey"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestParserClosesFiles(t *testing.T) {
+	Convey("Given a tracked parser over a local mirror", t, func() {
+		dir := mirror(t, map[string][]byte{
+			"cities500.zip":    zipped("cities500.txt", geonameRows),
+			"cities1000.zip":   zipped("cities1000.txt", strings.Replace(geonameRows, "\t1052\t", "\tmany\t", 1)),
+			"cities5000.zip":   zipped("other.txt", geonameRows),
+			Countries.String(): []byte(""),
+		})
+		files, p := track(NewDirParser(dir))
+		ctx := context.Background()
+
+		Convey("When an archive is parsed till the end", func() {
+			err := p.GetGeonames(Cities500, func(x *models.Geoname) error {
+				return nil
+			})
+
+			So(err, ShouldBeNil)
+			So(files, shouldHaveClosedAll)
+		})
+
+		Convey("When a handler fails", func() {
+			err := p.GetGeonames(Cities500, func(x *models.Geoname) error {
+				return errors.New("failure")
+			})
+
+			So(err, ShouldNotBeNil)
+			So(files, shouldHaveClosedAll)
+		})
+
+		Convey("When a handler panics", func() {
+			So(func() {
+				p.GetGeonames(Cities500, func(x *models.Geoname) error {
+					panic("failure")
+				})
+			}, ShouldPanicWith, "failure")
+			So(files, shouldHaveClosedAll)
+		})
+
+		Convey("When a record can not be decoded", func() {
+			err := p.GetGeonames(Cities1000, func(x *models.Geoname) error {
+				return nil
+			})
+
+			So(err, ShouldNotBeNil)
+			So(files, shouldHaveClosedAll)
+		})
+
+		Convey("When an archive does not contain the file", func() {
+			err := p.GetGeonames(Cities5000, func(x *models.Geoname) error {
+				return nil
+			})
+
+			So(err, ShouldNotBeNil)
+			So(files, shouldHaveClosedAll)
+		})
+
+		Convey("When a file is empty", func() {
+			err := p.GetCountries(func(x *models.Country) error {
+				return nil
+			})
+
+			So(err, ShouldBeNil)
+			So(files, shouldHaveClosedAll)
+		})
+
+		Convey("When the context is cancelled", func() {
+			ctx, cancel := context.WithCancel(ctx)
+			err := p.GetGeonamesContext(ctx, Cities500, func(x *models.Geoname) error {
+				cancel()
+				return nil
+			})
+
+			So(errors.Is(err, context.Canceled), ShouldBeTrue)
+			So(files, shouldHaveClosedAll)
+		})
+
+		Convey("When a range loop is left early", func() {
+			for range p.GeonamesSeq(ctx, Cities500) {
+				break
+			}
+
+			So(files, shouldHaveClosedAll)
+		})
+
+		Convey("When an iterator is closed before the end", func() {
+			it := NewIterator(p.GeonamesSeq(ctx, Cities500))
+			So(it.Next(), ShouldBeTrue)
+			So(it.Close(), ShouldBeNil)
+
+			So(files, shouldHaveClosedAll)
+		})
+	})
+	Convey("Given a parser whose files fail to close", t, func() {
+		dir := mirror(t, map[string][]byte{
+			"cities500.zip": zipped("cities500.txt", geonameRows),
+		})
+		failure := errors.New("close failure")
+		files, p := track(failClose(NewDirParser(dir), failure))
+		ctx := context.Background()
+
+		Convey("When a range loop is left early", func() {
+			So(func() {
+				for range p.GeonamesSeq(ctx, Cities500) {
+					break
+				}
+			}, ShouldNotPanic)
+			So(files, shouldHaveClosedAll)
+		})
+
+		Convey("When an iterator is closed before the end", func() {
+			it := NewIterator(p.GeonamesSeq(ctx, Cities500))
+			So(it.Next(), ShouldBeTrue)
+
+			So(it.Close(), ShouldEqual, failure)
+			So(it.Err(), ShouldEqual, failure)
+			So(files, shouldHaveClosedAll)
+		})
+
+		Convey("When an iterator is read till the end", func() {
+			it := NewIterator(p.GeonamesSeq(ctx, Cities500))
+			for it.Next() {
+			}
+
+			So(it.Err(), ShouldEqual, failure)
+			So(it.Close(), ShouldEqual, failure)
+		})
+	})
+}
+
+// failClose returns a parser whose files return err from Close after closing.
+func failClose(p Parser, err error) Parser {
+	return Parser(func(ctx context.Context, file string) (io.ReadCloser, error) {
+		r, perr := p(ctx, file)
+		if perr != nil {
+			return nil, perr
+		}
+		return failingCloser{r, err}, nil
+	})
+}
+
+type failingCloser struct {
+	io.ReadCloser
+	err error
+}
+
+func (f failingCloser) Close() error {
+	f.ReadCloser.Close()
+	return f.err
+}
@@ -11,6 +11,8 @@ isColloquial      : '1', if this alternate name is a colloquial or slang term. E
 isHistoric        : '1', if this alternate name is historic and was used in the past. Example 'Bombay' for 'Mumbai'.
 from		  : from period when the name was used
 to		  : to period when the name was used
+
+The deprecated alternateNames.zip has no from and to columns, they are left empty.
 */
 
 type AlternateName struct {
@@ -32,3 +34,112 @@ func (a *AlternateName) IsAlpha2() bool {
 func (a *AlternateName) IsAlpha3() bool {
 	return len(a.IsoLanguage) == 3
 }
+
+// AlternateNameKind tells what an alternate name is, as given by the isolanguage column.
+type AlternateNameKind int
+
+const (
+	KindName         AlternateNameKind = iota // a name in a language or without a language
+	KindPostalCode                            // post
+	KindAirportCode                           // iata, icao or faac
+	KindUNLocode                              // unlc
+	KindLink                                  // link, mostly to wikipedia
+	KindWikidataID                            // wkdt
+	KindAbbreviation                          // abbr
+	KindHistoric                              // a historic variant such as fr_1793 for French Revolution names
+)
+
+var kindNames = []string{"name", "postal code", "airport code", "UN/LOCODE", "link", "wikidata id", "abbreviation", "historic"}
+
+func (k AlternateNameKind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return "unknown"
+	}
+	return kindNames[k]
+}
+
+// Kind returns what the alternate name is.
+func (a *AlternateName) Kind() AlternateNameKind {
+	switch a.IsoLanguage {
+	case "post":
+		return KindPostalCode
+	case "iata", "icao", "faac":
+		return KindAirportCode
+	case "unlc":
+		return KindUNLocode
+	case "link":
+		return KindLink
+	case "wkdt":
+		return KindWikidataID
+	case "abbr":
+		return KindAbbreviation
+	case "fr_1793":
+		return KindHistoric
+	default:
+		return KindName
+	}
+}
+
+// AlternateNames are the alternate names of a single geoname.
+type AlternateNames []*AlternateName
+
+// WikidataID returns the id of the geoname in wikidata, e.g. "Q1863", or "" if there is none.
+func (n AlternateNames) WikidataID() string {
+	for _, a := range n {
+		if a.Kind() == KindWikidataID {
+			return a.Name
+		}
+	}
+	return ""
+}
+
+// AirportCodes returns the IATA, ICAO and FAA codes of the geoname.
+func (n AlternateNames) AirportCodes() []string {
+	return n.values(KindAirportCode)
+}
+
+// PostalCodes returns the postal codes of the geoname.
+func (n AlternateNames) PostalCodes() []string {
+	return n.values(KindPostalCode)
+}
+
+// Links returns the urls of the pages about the geoname.
+func (n AlternateNames) Links() []string {
+	return n.values(KindLink)
+}
+
+// Abbreviations returns the abbreviations of the geoname.
+func (n AlternateNames) Abbreviations() []string {
+	return n.values(KindAbbreviation)
+}
+
+// Names returns the names of the geoname in the language, e.g. "en".
+func (n AlternateNames) Names(language string) []string {
+	var names []string
+	for _, a := range n {
+		if a.Kind() == KindName && a.IsoLanguage == language {
+			names = append(names, a.Name)
+		}
+	}
+	return names
+}
+
+// Preferred returns the preferred name of the geoname in the language or nil if there is none.
+func (n AlternateNames) Preferred(language string) *AlternateName {
+	for _, a := range n {
+		if a.IsPreferred && a.Kind() == KindName && a.IsoLanguage == language {
+			return a
+		}
+	}
+	return nil
+}
+
+func (n AlternateNames) values(kind AlternateNameKind) []string {
+	var values []string
+	for _, a := range n {
+		if a.Kind() == kind {
+			values = append(values, a.Name)
+		}
+	}
+	return values
+}
@@ -1,23 +1,131 @@
 package models
 
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+)
+
 type Country struct {
-	Iso2Code           string  `csv:"ISO" valid:"required"`
-	Iso3Code           string  `csv:"ISO3" valid:"required"`
-	IsoNumeric         string  `csv:"ISO-Numeric" valid:"required"`
-	Fips               string  `csv:"fips"`
-	Name               string  `csv:"Country" valid:"required"`
-	Capital            string  `csv:"Capital"`
-	Area               float64 `csv:"Area(in sq km)"`
-	Population         int     `csv:"Population"`
-	Continent          string  `csv:"Continent" valid:"required"`
-	Tld                string  `csv:"tld"`
-	CurrencyCode       string  `csv:"CurrencyCode"`
-	CurrencyName       string  `csv:"CurrencyName"`
-	Phone              string  `csv:"Phone"`
-	PostalCodeFormat   string  `csv:"Postal Code Format"`
-	PostalCodeRegex    string  `csv:"Postal Code Regex"`
-	Languages          string  `csv:"Languages"`
-	GeonameID          int     `csv:"geonameid" valid:"required"`
-	Neighbours         string  `csv:"neighbours"`
-	EquivalentFipsCode string  `csv:"EquivalentFipsCode"`
+	Iso2Code           string       `csv:"ISO" valid:"required"`
+	Iso3Code           string       `csv:"ISO3" valid:"required"`
+	IsoNumeric         string       `csv:"ISO-Numeric" valid:"required"`
+	Fips               string       `csv:"fips"`
+	Name               string       `csv:"Country" valid:"required"`
+	Capital            string       `csv:"Capital"`
+	Area               float64      `csv:"Area(in sq km)"`
+	Population         int          `csv:"Population"`
+	Continent          string       `csv:"Continent" valid:"required"`
+	Tld                string       `csv:"tld"`
+	CurrencyCode       string       `csv:"CurrencyCode"`
+	CurrencyName       string       `csv:"CurrencyName"`
+	Phone              string       `csv:"Phone"`
+	PostalCodeFormat   string       `csv:"Postal Code Format"`
+	PostalCodeRegex    string       `csv:"Postal Code Regex"`
+	Languages          LanguageTags `csv:"Languages"`
+	GeonameID          int          `csv:"geonameid" valid:"required"`
+	Neighbours         StringList   `csv:"neighbours"`
+	EquivalentFipsCode string       `csv:"EquivalentFipsCode"`
+}
+
+// postalCodeRegexps caches the compiled postal code regexes by their pattern,
+// so the countries stay comparable and cheap to copy.
+var postalCodeRegexps sync.Map
+
+type compiledRegexp struct {
+	re  *regexp.Regexp
+	err error
+}
+
+// PostalCodeRegexp returns the compiled PostalCodeRegex or nil if the country has no postal codes.
+// The regex is compiled on the first call only.
+func (c *Country) PostalCodeRegexp() (*regexp.Regexp, error) {
+	if c.PostalCodeRegex == "" {
+		return nil, nil
+	}
+
+	if v, ok := postalCodeRegexps.Load(c.PostalCodeRegex); ok {
+		compiled := v.(compiledRegexp)
+		return compiled.re, compiled.err
+	}
+
+	re, err := regexp.Compile(c.PostalCodeRegex)
+	postalCodeRegexps.Store(c.PostalCodeRegex, compiledRegexp{re, err})
+	return re, err
+}
+
+// ValidatePostalCode reports whether the code matches the postal code regex of the country.
+// It is always false for a country without postal codes.
+func (c *Country) ValidatePostalCode(code string) bool {
+	re, err := c.PostalCodeRegexp()
+	if re == nil || err != nil {
+		return false
+	}
+	return re.MatchString(code)
+}
+
+// FormatPostalCode fits the letters and digits of the code into one of the alternatives of PostalCodeFormat,
+// where # stands for a digit and @ for a letter, e.g. "sw1a1aa" becomes "SW1A 1AA" in the United Kingdom.
+// Other characters of the format are added unless the code already has them.
+// It returns false if the code fits no alternative or does not match the regex of the country.
+func (c *Country) FormatPostalCode(code string) (string, bool) {
+	var chars []rune
+	for _, r := range strings.ToUpper(code) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			chars = append(chars, r)
+		}
+	}
+
+	for _, format := range c.postalCodeFormats() {
+		formatted, ok := fitPostalCode(format, chars)
+		if ok && c.ValidatePostalCode(formatted) {
+			return formatted, true
+		}
+	}
+	return "", false
+}
+
+// PostalCodeExamples returns a code in the shape of every alternative of PostalCodeFormat,
+// with 1 for the digits and A for the letters. They show the shape only and may not exist.
+func (c *Country) PostalCodeExamples() []string {
+	var examples []string
+	for _, format := range c.postalCodeFormats() {
+		examples = append(examples, strings.NewReplacer("#", "1", "@", "A").Replace(format))
+	}
+	return examples
+}
+
+func (c *Country) postalCodeFormats() []string {
+	if c.PostalCodeFormat == "" {
+		return nil
+	}
+	return strings.Split(c.PostalCodeFormat, "|")
+}
+
+func fitPostalCode(format string, chars []rune) (string, bool) {
+	b := strings.Builder{}
+	i := 0
+	for _, f := range format {
+		switch {
+		case f == '#':
+			if i == len(chars) || !unicode.IsDigit(chars[i]) {
+				return "", false
+			}
+			b.WriteRune(chars[i])
+			i++
+		case f == '@':
+			if i == len(chars) || !unicode.IsLetter(chars[i]) {
+				return "", false
+			}
+			b.WriteRune(chars[i])
+			i++
+		default:
+			if i < len(chars) && chars[i] == unicode.ToUpper(f) {
+				i++
+			}
+			b.WriteRune(f)
+		}
+	}
+	return b.String(), i == len(chars)
 }
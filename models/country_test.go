@@ -0,0 +1,91 @@
+package models
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestCountry(t *testing.T) {
+	Convey("Given the United Kingdom", t, func() {
+		c := Country{
+			Iso2Code:         "GB",
+			PostalCodeFormat: "@# #@@|@## #@@|@@# #@@|@@## #@@|@#@ #@@|@@#@ #@@|GIR0AA",
+			PostalCodeRegex:  `^([Gg][Ii][Rr]\s?0[Aa]{2})|((([A-Za-z][0-9]{1,2})|(([A-Za-z][A-Ha-hJ-Yj-y][0-9]{1,2})|(([A-Za-z][0-9][A-Za-z])|([A-Za-z][A-Ha-hJ-Yj-y][0-9]?[A-Za-z]))))\s?[0-9][A-Za-z]{2})$`,
+		}
+		So(c.Languages.UnmarshalCSV([]byte("en-GB,cy-GB,gd")), ShouldBeNil)
+		So(c.Neighbours.UnmarshalCSV([]byte("IE")), ShouldBeNil)
+
+		Convey("The languages should be split into language and region", func() {
+			So(c.Languages, ShouldResemble, LanguageTags{{"en", "GB"}, {"cy", "GB"}, {"gd", ""}})
+			data, err := c.Languages.MarshalCSV()
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, "en-GB,cy-GB,gd")
+		})
+
+		Convey("The neighbours should be a list", func() {
+			So(c.Neighbours, ShouldResemble, StringList{"IE"})
+		})
+
+		Convey("The postal codes should be validated by the regex", func() {
+			So(c.ValidatePostalCode("SW1A 1AA"), ShouldBeTrue)
+			So(c.ValidatePostalCode("12345"), ShouldBeFalse)
+
+			re, err := c.PostalCodeRegexp()
+			So(err, ShouldBeNil)
+			again, _ := c.PostalCodeRegexp()
+			So(again, ShouldEqual, re)
+		})
+
+		Convey("The postal codes should be formatted by the format", func() {
+			code, ok := c.FormatPostalCode("sw1a1aa")
+			So(ok, ShouldBeTrue)
+			So(code, ShouldEqual, "SW1A 1AA")
+
+			_, ok = c.FormatPostalCode("1234")
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("An example should be given for every format", func() {
+			examples := c.PostalCodeExamples()
+			So(len(examples), ShouldEqual, 7)
+			So(examples[0], ShouldEqual, "A1 1AA")
+		})
+	})
+
+	Convey("Given a country with a prefix in the format", t, func() {
+		c := Country{PostalCodeFormat: "AD###", PostalCodeRegex: `^(?:AD)*(\d{3})$`}
+
+		Convey("The prefix should be added unless the code has it", func() {
+			code, ok := c.FormatPostalCode("100")
+			So(ok, ShouldBeTrue)
+			So(code, ShouldEqual, "AD100")
+
+			code, ok = c.FormatPostalCode("ad 500")
+			So(ok, ShouldBeTrue)
+			So(code, ShouldEqual, "AD500")
+		})
+	})
+
+	Convey("Given a country without postal codes", t, func() {
+		c := Country{}
+
+		Convey("No postal code should be valid", func() {
+			re, err := c.PostalCodeRegexp()
+			So(re, ShouldBeNil)
+			So(err, ShouldBeNil)
+			So(c.ValidatePostalCode(""), ShouldBeFalse)
+			_, ok := c.FormatPostalCode("123")
+			So(ok, ShouldBeFalse)
+		})
+	})
+
+	Convey("Given a country with an invalid regex", t, func() {
+		c := Country{PostalCodeRegex: "^(\\d{5}$"}
+
+		Convey("The error should be returned", func() {
+			_, err := c.PostalCodeRegexp()
+			So(err, ShouldNotBeNil)
+			So(c.ValidatePostalCode("12345"), ShouldBeFalse)
+		})
+	})
+}
@@ -12,11 +12,22 @@ type (
 	GeoNameFile     DumpFile
 	AltNameFile     DumpFile
 	FeatureCodeFile DumpFile
+	PostalCodeFile  DumpFile
 )
 
+// TextFilename returns the name of the file in the archive: the name of the archive with the .txt extension
+// instead of .zip or .csv.zip, except for shapes_simplified_low.json.zip which contains a .json file.
 func (d DumpFile) TextFilename() string {
 	if d.IsArchive() {
-		return strings.Replace(filepath.Base(d.String()), ".zip", ".txt", 1)
+		name := strings.TrimSuffix(filepath.Base(d.String()), ".zip")
+		switch filepath.Ext(name) {
+		case ".json":
+			return name
+		case ".csv":
+			return strings.TrimSuffix(name, ".csv") + ".txt"
+		default:
+			return name + ".txt"
+		}
 	} else {
 		return d.String()
 	}
@@ -35,3 +46,12 @@ func (d DumpFile) WithLastDate() DumpFile {
 	t := time.Now().In(loc).AddDate(0, 0, -1)
 	return DumpFile(fmt.Sprintf(d.String(), t.Format("2006-01-02")))
 }
+
+// Language returns the language of the feature codes file, e.g. "en" for featureCodes_en.txt.
+func (f FeatureCodeFile) Language() string {
+	name := strings.TrimSuffix(filepath.Base(string(f)), filepath.Ext(string(f)))
+	if i := strings.LastIndex(name, "_"); i >= 0 {
+		return name[i+1:]
+	}
+	return ""
+}
@@ -1,7 +1,14 @@
 package models
 
+import "github.com/mkrou/geonames/featureclass"
+
 type FeatureCode struct {
 	Code        string `csv:"code" valid:"required"`
 	Name        string `csv:"name" valid:"required"`
 	Description string `csv:"description"`
 }
+
+// Split splits the code such as "P.PPL" into its class and the code of the class.
+func (f *FeatureCode) Split() (featureclass.Class, string) {
+	return featureclass.Split(f.Code)
+}
@@ -1,5 +1,7 @@
 package models
 
+import "github.com/mkrou/geonames/featureclass"
+
 /*
 geonameid         : integer id of record in geonames database
 name              : name of geographical point (utf8) varchar(200)
@@ -23,23 +25,38 @@ modification date : date of last modification in yyyy-MM-dd format
 */
 
 type Geoname struct {
-	Id                    int     `csv:"geonameid" valid:"required"`
-	Name                  string  `csv:"name" valid:"required"`
-	AsciiName             string  `csv:"asciiname"`
-	AlternateNames        string  `csv:"alternatenames"`
-	Latitude              float64 `csv:"latitude"`
-	Longitude             float64 `csv:"longitude"`
-	Class                 string  `csv:"feature class"`
-	Code                  string  `csv:"feature code"`
-	CountryCode           string  `csv:"country code"`
-	AlternateCountryCodes string  `csv:"cc2"`
-	Admin1Code            string  `csv:"admin1 code"`
-	Admin2Code            string  `csv:"admin2 code"`
-	Admin3Code            string  `csv:"admin3 code"`
-	Admin4Code            string  `csv:"admin4 code"`
-	Population            int     `csv:"population"`
-	Elevation             int     `csv:"elevation,omitempty"`
-	DigitalElevationModel int     `csv:"dem,omitempty"`
-	Timezone              string  `csv:"timezone"`
-	ModificationDate      Time    `csv:"modification date" valid:"required"`
+	Id                    int        `csv:"geonameid" valid:"required"`
+	Name                  string     `csv:"name" valid:"required"`
+	AsciiName             string     `csv:"asciiname"`
+	AlternateNames        StringList `csv:"alternatenames"`
+	Latitude              float64    `csv:"latitude"`
+	Longitude             float64    `csv:"longitude"`
+	Class                 string     `csv:"feature class"`
+	Code                  string     `csv:"feature code"`
+	CountryCode           string     `csv:"country code"`
+	AlternateCountryCodes StringList `csv:"cc2"`
+	Admin1Code            string     `csv:"admin1 code"`
+	Admin2Code            string     `csv:"admin2 code"`
+	Admin3Code            string     `csv:"admin3 code"`
+	Admin4Code            string     `csv:"admin4 code"`
+	Population            int        `csv:"population"`
+	Elevation             int        `csv:"elevation,omitempty"`
+	DigitalElevationModel int        `csv:"dem,omitempty"`
+	Timezone              string     `csv:"timezone"`
+	ModificationDate      Time       `csv:"modification date" valid:"required"`
+}
+
+// FeatureClass returns the typed feature class of the geoname.
+func (g *Geoname) FeatureClass() featureclass.Class {
+	return featureclass.Class(g.Class)
+}
+
+// IsPopulatedPlace reports whether the geoname is a city, a village or another populated place.
+func (g *Geoname) IsPopulatedPlace() bool {
+	return g.FeatureClass() == featureclass.PopulatedPlace
+}
+
+// IsAdministrative reports whether the geoname is a country, a state or another administrative division.
+func (g *Geoname) IsAdministrative() bool {
+	return g.FeatureClass() == featureclass.Administrative
 }
@@ -1,8 +1,48 @@
 package models
 
+import "strings"
+
 type Language struct {
 	Iso639_1 string `csv:"ISO 639-1"`
 	Iso639_2 string `csv:"ISO 639-2"`
 	Iso639_3 string `csv:"ISO 639-3" valid:"required"`
 	Name     string `csv:"Language Name" valid:"required"`
 }
+
+// LanguageTag is a language of a country such as en-US, split into the language and the region.
+type LanguageTag struct {
+	Language string
+	Region   string
+}
+
+func (t LanguageTag) String() string {
+	if t.Region == "" {
+		return t.Language
+	}
+	return t.Language + "-" + t.Region
+}
+
+// LanguageTags is a comma separated column of language tags such as the languages of a Country.
+type LanguageTags []LanguageTag
+
+func (l LanguageTags) MarshalCSV() ([]byte, error) {
+	tags := make([]string, len(l))
+	for i, t := range l {
+		tags[i] = t.String()
+	}
+	return []byte(strings.Join(tags, ",")), nil
+}
+
+func (l *LanguageTags) UnmarshalCSV(data []byte) error {
+	var tags StringList
+	if err := tags.UnmarshalCSV(data); err != nil {
+		return err
+	}
+
+	*l = nil
+	for _, tag := range tags {
+		language, region, _ := strings.Cut(tag, "-")
+		*l = append(*l, LanguageTag{Language: language, Region: region})
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package models
+
+import "strings"
+
+// StringList is a comma separated column such as the alternate names of a Geoname.
+// An empty column is decoded to a nil list.
+type StringList []string
+
+func (l StringList) MarshalCSV() ([]byte, error) {
+	return []byte(strings.Join(l, ",")), nil
+}
+
+func (l *StringList) UnmarshalCSV(data []byte) error {
+	if len(data) == 0 {
+		*l = nil
+		return nil
+	}
+	*l = strings.Split(string(data), ",")
+	return nil
+}
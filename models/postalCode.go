@@ -0,0 +1,31 @@
+package models
+
+/*
+country code      : iso country code, 2 characters
+postal code       : varchar(20)
+place name        : varchar(180)
+admin name1       : 1. order subdivision (state) varchar(100)
+admin code1       : 1. order subdivision (state) varchar(20)
+admin name2       : 2. order subdivision (county/province) varchar(100)
+admin code2       : 2. order subdivision (county/province) varchar(20)
+admin name3       : 3. order subdivision (community) varchar(100)
+admin code3       : 3. order subdivision (community) varchar(20)
+latitude          : estimated latitude (wgs84)
+longitude         : estimated longitude (wgs84)
+accuracy          : accuracy of lat/lng from 1=estimated, 4=geonameid, 6=centroid of addresses or shape
+*/
+
+type PostalCode struct {
+	CountryCode string  `csv:"country code" valid:"required"`
+	PostalCode  string  `csv:"postal code" valid:"required"`
+	PlaceName   string  `csv:"place name"`
+	Admin1Name  string  `csv:"admin name1"`
+	Admin1Code  string  `csv:"admin code1"`
+	Admin2Name  string  `csv:"admin name2"`
+	Admin2Code  string  `csv:"admin code2"`
+	Admin3Name  string  `csv:"admin name3"`
+	Admin3Code  string  `csv:"admin code3"`
+	Latitude    float64 `csv:"latitude,omitempty"`
+	Longitude   float64 `csv:"longitude,omitempty"`
+	Accuracy    int     `csv:"accuracy,omitempty"`
+}
@@ -1,6 +1,47 @@
 package models
 
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mkrou/geonames/geometry"
+)
+
 type Shape struct {
 	GeonameId int    `csv:"geoNameId" valid:"required"`
 	GeoJson   string `csv:"geoJSON" valid:"required"`
 }
+
+// Geometry decodes the GeoJSON of the shape. See geometry.Decode.
+func (s *Shape) Geometry() (geometry.MultiPolygon, error) {
+	return geometry.Decode([]byte(s.GeoJson))
+}
+
+// SimplifiedShape is a feature of the simplified shapes GeoJSON FeatureCollection.
+type SimplifiedShape struct {
+	GeonameId int
+	Geometry  geometry.MultiPolygon
+}
+
+func (s *SimplifiedShape) UnmarshalJSON(data []byte) error {
+	var feature struct {
+		Geometry   json.RawMessage `json:"geometry"`
+		Properties struct {
+			GeonameId json.Number `json:"geoNameId"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &feature); err != nil {
+		return err
+	}
+
+	id, err := feature.Properties.GeonameId.Int64()
+	if err != nil {
+		return fmt.Errorf("models: feature has an invalid geoNameId %q", feature.Properties.GeonameId)
+	}
+	g, err := geometry.Decode(feature.Geometry)
+	if err != nil {
+		return fmt.Errorf("models: feature %d: %w", id, err)
+	}
+
+	s.GeonameId, s.Geometry = int(id), g
+	return nil
+}
@@ -1,45 +1,84 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
+
+// Precision tells which parts of a Time were given in the dump.
+type Precision int
+
+const (
+	PrecisionNone  Precision = iota // the value was empty
+	PrecisionYear                   // e.g. 1795
+	PrecisionMonth                  // e.g. 179503
+	PrecisionDay                    // e.g. 1795-03-14
+)
+
+var layouts = []struct {
+	layout    string
+	precision Precision
+}{
+	{"2006-01-02", PrecisionDay},
+	{"02 January 2006", PrecisionDay},
+	{"2006", PrecisionYear},
+	{"200601", PrecisionMonth},
+	{"2006-01", PrecisionMonth},
+	{"20060102", PrecisionDay},
+	{"02-01-2006", PrecisionDay},
+}
 
 type Time struct {
 	time.Time
+	Precision Precision
 }
 
 func (t Time) MarshalCSV() ([]byte, error) {
-	return []byte(t.String()), nil
+	switch t.Precision {
+	case PrecisionYear:
+		return []byte(t.Format("2006")), nil
+	case PrecisionMonth:
+		return []byte(t.Format("2006-01")), nil
+	case PrecisionDay:
+		return []byte(t.Format("2006-01-02")), nil
+	default:
+		return []byte{}, nil
+	}
 }
 
 func (t *Time) UnmarshalCSV(data []byte) error {
 	date := string(data)
 	if date == "" {
+		*t = Time{}
 		return nil
 	}
 
-	var err error
-	if t.Time, err = time.Parse("2006-01-02", date); err == nil {
-		return nil
-	}
-
-	if t.Time, err = time.Parse("02 January 2006", date); err == nil {
-		return nil
-	}
-
-	if t.Time, err = time.Parse("2006", date); err == nil {
-		return nil
-	}
-
-	if t.Time, err = time.Parse("200601", date); err == nil {
-		return nil
+	for _, l := range layouts {
+		if parsed, err := time.Parse(l.layout, date); err == nil {
+			*t = Time{Time: parsed, Precision: l.precision}
+			return nil
+		}
 	}
 
-	if t.Time, err = time.Parse("20060102", date); err == nil {
-		return nil
-	}
+	return fmt.Errorf("models: unknown time format %q", date)
+}
 
-	if t.Time, err = time.Parse("02-01-2006", date); err == nil {
-		return nil
+// End returns the first moment after the period given by the time and its precision,
+// e.g. 1796-01-01 for the year 1795.
+func (t Time) End() time.Time {
+	switch t.Precision {
+	case PrecisionYear:
+		return t.AddDate(1, 0, 0)
+	case PrecisionMonth:
+		return t.AddDate(0, 1, 0)
+	case PrecisionDay:
+		return t.AddDate(0, 0, 1)
+	default:
+		return t.Time
 	}
+}
 
-	return nil
+// Contains reports whether u falls within the period given by the time and its precision.
+func (t Time) Contains(u time.Time) bool {
+	return t.Precision != PrecisionNone && !u.Before(t.Time) && u.Before(t.End())
 }
@@ -0,0 +1,71 @@
+package models
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+	"time"
+)
+
+func TestTime(t *testing.T) {
+	Convey("Given dates in the formats of the dump", t, func() {
+		cases := []struct {
+			raw       string
+			expected  time.Time
+			precision Precision
+		}{
+			{"", time.Time{}, PrecisionNone},
+			{"1795", time.Date(1795, 1, 1, 0, 0, 0, 0, time.UTC), PrecisionYear},
+			{"179503", time.Date(1795, 3, 1, 0, 0, 0, 0, time.UTC), PrecisionMonth},
+			{"1795-03", time.Date(1795, 3, 1, 0, 0, 0, 0, time.UTC), PrecisionMonth},
+			{"17950314", time.Date(1795, 3, 14, 0, 0, 0, 0, time.UTC), PrecisionDay},
+			{"1795-03-14", time.Date(1795, 3, 14, 0, 0, 0, 0, time.UTC), PrecisionDay},
+			{"14-03-1795", time.Date(1795, 3, 14, 0, 0, 0, 0, time.UTC), PrecisionDay},
+			{"14 March 1795", time.Date(1795, 3, 14, 0, 0, 0, 0, time.UTC), PrecisionDay},
+		}
+
+		Convey("When they are unmarshalled", func() {
+			for _, c := range cases {
+				x := Time{}
+				err := x.UnmarshalCSV([]byte(c.raw))
+
+				So(err, ShouldBeNil)
+				So(x.Time, ShouldEqual, c.expected)
+				So(x.Precision, ShouldEqual, c.precision)
+			}
+		})
+
+		Convey("When they are marshalled back", func() {
+			for _, c := range cases[:4] {
+				x := Time{}
+				So(x.UnmarshalCSV([]byte(c.raw)), ShouldBeNil)
+				data, err := x.MarshalCSV()
+
+				So(err, ShouldBeNil)
+				y := Time{}
+				So(y.UnmarshalCSV(data), ShouldBeNil)
+				So(y, ShouldResemble, x)
+			}
+		})
+	})
+
+	Convey("Given a date in an unknown format", t, func() {
+		x := Time{}
+		err := x.UnmarshalCSV([]byte("spring of 1795"))
+
+		Convey("The error should be returned", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, `"spring of 1795"`)
+		})
+	})
+
+	Convey("Given a period of a year", t, func() {
+		x := Time{}
+		So(x.UnmarshalCSV([]byte("1795")), ShouldBeNil)
+
+		Convey("It should contain the whole year", func() {
+			So(x.Contains(time.Date(1795, 12, 31, 23, 0, 0, 0, time.UTC)), ShouldBeTrue)
+			So(x.Contains(time.Date(1796, 1, 1, 0, 0, 0, 0, time.UTC)), ShouldBeFalse)
+			So(x.End(), ShouldEqual, time.Date(1796, 1, 1, 0, 0, 0, 0, time.UTC))
+		})
+	})
+}
@@ -0,0 +1,23 @@
+package geonames
+
+import (
+	"context"
+	"github.com/mkrou/geonames/models"
+)
+
+// AlternateNamesByGeoname reads the alternate names of the archive into memory, grouped by their geoname.
+// Only the names accepted by filter are kept, all of them if filter is nil.
+// The full archives are large, so filter them to the kinds or languages you need.
+func (p Parser) AlternateNamesByGeoname(ctx context.Context, archive models.AltNameFile, filter func(*models.AlternateName) bool) (map[int]models.AlternateNames, error) {
+	names := map[int]models.AlternateNames{}
+	err := p.GetAlternateNamesContext(ctx, archive, func(a *models.AlternateName) error {
+		if filter == nil || filter(a) {
+			names[a.GeonameId] = append(names[a.GeonameId], a)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return names, nil
+}
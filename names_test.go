@@ -0,0 +1,65 @@
+package geonames
+
+import (
+	"context"
+	"github.com/mkrou/geonames/models"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+const alternateNameKindRows = "10\t3039154\twkdt\tQ1863\t\t\t\t\t\t\n" +
+	"11\t3039154\tlink\thttps://en.wikipedia.org/wiki/El_Tarter\t\t\t\t\t\t\n" +
+	"12\t3039154\ten\tEl Tarter\t1\t\t\t\t\t\n" +
+	"13\t6299404\tiata\tALV\t\t\t\t\t\t\n" +
+	"14\t6299404\ticao\tLEAN\t\t\t\t\t\t\n" +
+	"15\t3039163\tfr_1793\tSant Julià\t\t\t\t1\t1793\t1795\n"
+
+func TestAlternateNamesByGeoname(t *testing.T) {
+	Convey("Given a local mirror with alternate names of every kind", t, func() {
+		dir := mirror(t, map[string][]byte{
+			"alternatenames/AD.zip": zipped("AD.txt", alternateNameKindRows),
+			"alternateNames.zip":    zipped("alternateNames.txt", "1\t3039154\ten\tEl Tarter\t1\t\t\t\n"),
+		})
+		p := NewDirParser(dir)
+
+		Convey("When they are grouped by geoname", func() {
+			names, err := p.AlternateNamesByGeoname(context.Background(), "alternatenames/AD.zip", nil)
+
+			Convey("The accessors should classify them", func() {
+				So(err, ShouldBeNil)
+				So(len(names), ShouldEqual, 3)
+				So(names[3039154].WikidataID(), ShouldEqual, "Q1863")
+				So(names[3039154].Links(), ShouldResemble, []string{"https://en.wikipedia.org/wiki/El_Tarter"})
+				So(names[3039154].Names("en"), ShouldResemble, []string{"El Tarter"})
+				So(names[3039154].Preferred("en").Id, ShouldEqual, 12)
+				So(names[6299404].AirportCodes(), ShouldResemble, []string{"ALV", "LEAN"})
+				So(names[6299404].WikidataID(), ShouldBeEmpty)
+				So(names[3039163][0].Kind(), ShouldEqual, models.KindHistoric)
+				So(names[3039163][0].From.Year(), ShouldEqual, 1793)
+			})
+		})
+
+		Convey("When they are filtered", func() {
+			names, err := p.AlternateNamesByGeoname(context.Background(), "alternatenames/AD.zip", func(a *models.AlternateName) bool {
+				return a.Kind() == models.KindAirportCode
+			})
+
+			So(err, ShouldBeNil)
+			So(len(names), ShouldEqual, 1)
+			So(len(names[6299404]), ShouldEqual, 2)
+		})
+
+		Convey("When the deprecated archive without periods is parsed", func() {
+			var x *models.AlternateName
+			err := p.GetAlternateNames(AlternateNamesV1, func(a *models.AlternateName) error {
+				x = a
+				return nil
+			})
+
+			So(err, ShouldBeNil)
+			So(x.Name, ShouldEqual, "El Tarter")
+			So(x.IsPreferred, ShouldBeTrue)
+			So(x.From.IsZero(), ShouldBeTrue)
+		})
+	})
+}
@@ -0,0 +1,128 @@
+package geonames
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Option configures the parsers returned by NewParser and NewCachedParser.
+type Option func(*client)
+
+// WithHttpClient sets the client used for the requests instead of http.DefaultClient.
+func WithHttpClient(c *http.Client) Option {
+	return func(cl *client) {
+		cl.http = c
+	}
+}
+
+// WithBaseUrl sets the url of the dump directory, e.g. of an internal mirror, instead of Url.
+func WithBaseUrl(url string) Option {
+	return func(cl *client) {
+		if !strings.HasSuffix(url, "/") {
+			url += "/"
+		}
+		cl.baseUrl = url
+	}
+}
+
+// WithUserAgent sets the User-Agent header of the requests.
+func WithUserAgent(userAgent string) Option {
+	return func(cl *client) {
+		cl.userAgent = userAgent
+	}
+}
+
+// WithRetries retries a request up to n times when it fails with a connection error
+// or a 5xx status code before the body starts streaming.
+// The delay before the first retry is backoff and doubles with every next one.
+func WithRetries(n int, backoff time.Duration) Option {
+	return func(cl *client) {
+		cl.retries = n
+		cl.backoff = backoff
+	}
+}
+
+type client struct {
+	http      *http.Client
+	baseUrl   string
+	userAgent string
+	retries   int
+	backoff   time.Duration
+	resumes   int
+	// cacheCompletion is used by NewCachedParser only
+	cacheCompletion int64
+}
+
+func newClient(options []Option) *client {
+	c := &client{
+		http:            http.DefaultClient,
+		baseUrl:         Url,
+		resumes:         defaultResumes,
+		cacheCompletion: defaultCacheCompletion,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+func (c *client) get(ctx context.Context, file string, header http.Header) (*http.Response, error) {
+	u, err := c.url(file)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	delay := c.backoff
+	for attempt := 0; ; attempt++ {
+		resp, err := c.http.Do(req)
+		if attempt == c.retries || !retryable(ctx, resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+func retryable(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil
+	}
+	return resp.StatusCode >= http.StatusInternalServerError
+}
+
+// url resolves the file against the base url, so files outside of the dump directory
+// such as ../zip/AD.zip are requested from their canonical url.
+func (c *client) url(file string) (string, error) {
+	base, err := url.Parse(c.baseUrl)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(file)
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(ref).String(), nil
+}
@@ -0,0 +1,77 @@
+package geonames
+
+import (
+	"github.com/mkrou/geonames/models"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type countingTransport struct {
+	requests int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestParserOptions(t *testing.T) {
+	Convey("Given a mirror that fails before it responds", t, func() {
+		archive := zipped("cities500.txt", geonameRows)
+		failures, requests := 0, 0
+		var userAgent, path string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			userAgent, path = r.UserAgent(), r.URL.Path
+			if requests <= failures {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write(archive)
+		}))
+		defer srv.Close()
+
+		transport := &countingTransport{}
+		options := []Option{
+			WithBaseUrl(srv.URL + "/mirror"),
+			WithHttpClient(&http.Client{Transport: transport}),
+			WithUserAgent("geonames-test"),
+			WithRetries(2, time.Millisecond),
+		}
+		parse := func() error {
+			return NewParser(options...).GetGeonames(Cities500, func(x *models.Geoname) error {
+				return nil
+			})
+		}
+
+		Convey("When it fails less times than the retries allow", func() {
+			failures = 2
+			err := parse()
+
+			Convey("The archive should be parsed", func() {
+				So(err, ShouldBeNil)
+				So(requests, ShouldEqual, 3)
+			})
+
+			Convey("The options should be applied to every request", func() {
+				So(transport.requests, ShouldEqual, 3)
+				So(userAgent, ShouldEqual, "geonames-test")
+				So(path, ShouldEqual, "/mirror/cities500.zip")
+			})
+		})
+
+		Convey("When it fails more times than the retries allow", func() {
+			failures = 3
+			err := parse()
+
+			Convey("The last status should be returned", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "unexpected code 503")
+				So(requests, ShouldEqual, 3)
+			})
+		})
+	})
+}
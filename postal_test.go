@@ -0,0 +1,82 @@
+package geonames
+
+import (
+	"context"
+	"github.com/mkrou/geonames/models"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const andorraPostalCodeRows = "AD\tAD100\tCanillo\t\t\t\t\t\t\t42.5833\t1.6667\t6\n"
+
+const postalCodeRows = andorraPostalCodeRows +
+	"GB\tSW1A 1AA\tLondon\tEngland\tENG\tGreater London\t11609024\tCity of Westminster\tE09000033\t51.501\t-0.1416\t\n"
+
+func TestPostalCodes(t *testing.T) {
+	Convey("Given a local mirror with the zip directory inside", t, func() {
+		dir := mirror(t, map[string][]byte{
+			"cities500.zip":       zipped("cities500.txt", geonameRows),
+			"zip/AD.zip":          zipped("AD.txt", andorraPostalCodeRows),
+			"zip/GB_full.csv.zip": zipped("GB_full.txt", postalCodeRows),
+		})
+		p := NewDirParser(dir)
+
+		Convey("When the postal codes of a country are parsed", func() {
+			var x []*models.PostalCode
+			err := p.GetPostalCodes(CountryPostalCodes("ad"), func(c *models.PostalCode) error {
+				x = append(x, c)
+				return nil
+			})
+
+			Convey("They should be read from the zip directory", func() {
+				So(err, ShouldBeNil)
+				So(len(x), ShouldEqual, 1)
+				So(x[0].PostalCode, ShouldEqual, "AD100")
+				So(x[0].Latitude, ShouldEqual, 42.5833)
+				So(x[0].Accuracy, ShouldEqual, 6)
+			})
+		})
+
+		Convey("When a full csv archive is parsed", func() {
+			var x *models.PostalCode
+			err := p.GetPostalCodes(PostalCodesGBFull, func(c *models.PostalCode) error {
+				x = c
+				return nil
+			})
+
+			So(err, ShouldBeNil)
+			So(x.PostalCode, ShouldEqual, "SW1A 1AA")
+			So(x.Admin3Name, ShouldEqual, "City of Westminster")
+			So(x.Accuracy, ShouldEqual, 0)
+		})
+
+		Convey("When a file outside of the mirror is requested", func() {
+			_, err := p(context.Background(), "../../etc/passwd")
+
+			Convey("It should be rejected", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "outside")
+			})
+		})
+	})
+
+	Convey("Given a mirror served over http", t, func() {
+		var path string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			w.Write(zipped("AD.txt", andorraPostalCodeRows))
+		}))
+		defer srv.Close()
+
+		Convey("The archive should be requested from the zip directory next to the base url", func() {
+			err := NewParser(WithBaseUrl(srv.URL+"/export/dump")).GetPostalCodes(CountryPostalCodes("AD"), func(c *models.PostalCode) error {
+				return nil
+			})
+
+			So(err, ShouldBeNil)
+			So(path, ShouldEqual, "/export/zip/AD.zip")
+		})
+	})
+}
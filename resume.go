@@ -0,0 +1,100 @@
+package geonames
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// defaultResumes is the number of times a download is resumed after a dropped connection.
+const defaultResumes = 3
+
+// WithResumes sets how many times a download interrupted in the middle of the body
+// is continued with a Range request from the last received byte. Zero disables resuming.
+func WithResumes(n int) Option {
+	return func(cl *client) {
+		cl.resumes = n
+	}
+}
+
+// resumableBody continues reading a response body with Range requests
+// after the connection has been dropped, so the reader never sees the gap.
+type resumableBody struct {
+	ctx       context.Context
+	client    *client
+	file      string
+	validator string
+	body      io.ReadCloser
+	offset    int64
+	resumes   int
+}
+
+func (c *client) resumable(ctx context.Context, file string, resp *http.Response) io.ReadCloser {
+	if c.resumes == 0 {
+		return resp.Body
+	}
+
+	validator := resp.Header.Get("ETag")
+	if validator == "" || strings.HasPrefix(validator, "W/") {
+		validator = resp.Header.Get("Last-Modified")
+	}
+
+	return &resumableBody{
+		ctx:       ctx,
+		client:    c,
+		file:      file,
+		validator: validator,
+		body:      resp.Body,
+		resumes:   c.resumes,
+	}
+}
+
+func (b *resumableBody) Read(p []byte) (int, error) {
+	for {
+		n, err := b.body.Read(p)
+		b.offset += int64(n)
+		if err == nil || err == io.EOF || b.resumes == 0 || b.ctx.Err() != nil {
+			return n, err
+		}
+
+		if b.resume() != nil {
+			return n, err
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+func (b *resumableBody) resume() error {
+	b.resumes--
+	b.body.Close()
+
+	header := http.Header{}
+	header.Set("Range", fmt.Sprintf("bytes=%d-", b.offset))
+	if b.validator != "" {
+		header.Set("If-Range", b.validator)
+	}
+
+	resp, err := b.client.get(b.ctx, b.file, header)
+	if err != nil {
+		b.body = http.NoBody
+		return err
+	}
+
+	if resp.StatusCode != http.StatusPartialContent ||
+		!strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", b.offset)) {
+		resp.Body.Close()
+		b.body = http.NoBody
+		return fmt.Errorf("Page %s can not be resumed at byte %d", resp.Request.URL, b.offset)
+	}
+
+	b.body = resp.Body
+	return nil
+}
+
+func (b *resumableBody) Close() error {
+	return b.body.Close()
+}
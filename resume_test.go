@@ -0,0 +1,80 @@
+package geonames
+
+import (
+	"bytes"
+	"github.com/mkrou/geonames/models"
+	. "github.com/smartystreets/goconvey/convey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestResumableDownload(t *testing.T) {
+	Convey("Given a dump server that drops the connection in the middle of the archive", t, func() {
+		archive := zipped("cities500.txt", geonameFixture(5000))
+		modified := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+		drops, ranges := 2, 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Range") != "" {
+				ranges++
+			}
+			if drops > 0 {
+				drops--
+				w = &droppingWriter{ResponseWriter: w, limit: len(archive) / 3}
+			}
+			http.ServeContent(w, r, "cities500.zip", modified, bytes.NewReader(archive))
+		}))
+		defer srv.Close()
+
+		Convey("When the archive is parsed", func() {
+			ids := map[int]int{}
+			err := NewParser(WithBaseUrl(srv.URL)).GetGeonames(Cities500, func(x *models.Geoname) error {
+				ids[x.Id]++
+				return nil
+			})
+
+			Convey("The download should be resumed with a range request", func() {
+				So(err, ShouldBeNil)
+				So(ranges, ShouldEqual, 2)
+			})
+
+			Convey("Every record should be delivered exactly once", func() {
+				duplicates := 0
+				for _, n := range ids {
+					duplicates += n - 1
+				}
+				So(len(ids), ShouldEqual, 5000)
+				So(duplicates, ShouldEqual, 0)
+			})
+		})
+
+		Convey("When resuming is disabled", func() {
+			err := NewParser(WithBaseUrl(srv.URL), WithResumes(0)).GetGeonames(Cities500, func(x *models.Geoname) error {
+				return nil
+			})
+
+			Convey("The error should be returned", func() {
+				So(err, ShouldNotBeNil)
+				So(ranges, ShouldEqual, 0)
+			})
+		})
+	})
+}
+
+// droppingWriter aborts the connection after limit bytes of the body.
+type droppingWriter struct {
+	http.ResponseWriter
+	limit int
+}
+
+func (w *droppingWriter) Write(p []byte) (int, error) {
+	if len(p) <= w.limit {
+		w.limit -= len(p)
+		return w.ResponseWriter.Write(p)
+	}
+
+	w.ResponseWriter.Write(p[:w.limit])
+	w.ResponseWriter.(http.Flusher).Flush()
+	panic(http.ErrAbortHandler)
+}
@@ -0,0 +1,103 @@
+package geonames
+
+import (
+	"context"
+	"github.com/mkrou/geonames/models"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+const simplifiedShapes = `{"type":"FeatureCollection","features":[
+{"type":"Feature","geometry":{"type":"Polygon","coordinates":[[[1.4,42.4],[1.8,42.4],[1.8,42.7],[1.4,42.7],[1.4,42.4]]]},"properties":{"geoNameId":"3041565"}},
+{"type":"Feature","geometry":{"type":"MultiPolygon","coordinates":[[[[9,9],[11,9],[11,11],[9,11],[9,9]]]]},"properties":{"geoNameId":290557}}
+]}`
+
+func TestSimplifiedShapes(t *testing.T) {
+	Convey("Given a local mirror with the simplified shapes", t, func() {
+		dir := mirror(t, map[string][]byte{
+			SimplifiedShapes.String(): zipped("shapes_simplified_low.json", simplifiedShapes),
+		})
+		tr, p := track(NewDirParser(dir))
+
+		Convey("When they are parsed", func() {
+			var x []*models.SimplifiedShape
+			err := p.GetSimplifiedShapes(func(s *models.SimplifiedShape) error {
+				x = append(x, s)
+				return nil
+			})
+
+			Convey("Every feature should be decoded with its geometry", func() {
+				So(err, ShouldBeNil)
+				So(len(x), ShouldEqual, 2)
+				So(x[0].GeonameId, ShouldEqual, 3041565)
+				So(x[0].Geometry.Contains(42.5, 1.5), ShouldBeTrue)
+				So(x[1].GeonameId, ShouldEqual, 290557)
+				So(x[1].Geometry.Contains(10, 10), ShouldBeTrue)
+			})
+
+			Convey("The file should be closed", func() {
+				So(tr, shouldHaveClosedAll)
+			})
+		})
+
+		Convey("When a handler returns ErrStop", func() {
+			count := 0
+			err := p.GetSimplifiedShapes(func(s *models.SimplifiedShape) error {
+				count++
+				return ErrStop
+			})
+
+			So(err, ShouldBeNil)
+			So(count, ShouldEqual, 1)
+			So(tr, shouldHaveClosedAll)
+		})
+
+		Convey("When they are ranged over", func() {
+			var ids []int
+			for s, err := range p.SimplifiedShapesSeq(context.Background()) {
+				So(err, ShouldBeNil)
+				ids = append(ids, s.GeonameId)
+			}
+
+			So(ids, ShouldResemble, []int{3041565, 290557})
+			So(tr, shouldHaveClosedAll)
+		})
+
+		Convey("When the loop is left early", func() {
+			count := 0
+			for _, err := range p.SimplifiedShapesSeq(context.Background()) {
+				So(err, ShouldBeNil)
+				count++
+				break
+			}
+
+			So(count, ShouldEqual, 1)
+			So(tr, shouldHaveClosedAll)
+		})
+	})
+
+	Convey("Given a truncated collection", t, func() {
+		dir := mirror(t, map[string][]byte{
+			SimplifiedShapes.String(): zipped("shapes_simplified_low.json", simplifiedShapes[:300]),
+		})
+		p := NewDirParser(dir)
+
+		Convey("The error should be returned", func() {
+			err := p.GetSimplifiedShapes(func(s *models.SimplifiedShape) error {
+				return nil
+			})
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("The sequence should yield the error with a nil value", func() {
+			var errs []error
+			for s, err := range p.SimplifiedShapesSeq(context.Background()) {
+				if err != nil {
+					So(s, ShouldBeNil)
+					errs = append(errs, err)
+				}
+			}
+			So(len(errs), ShouldEqual, 1)
+		})
+	})
+}
@@ -0,0 +1,253 @@
+// Package spatial finds the geonames near a coordinate.
+package spatial
+
+import (
+	"container/heap"
+	"github.com/mkrou/geonames/featureclass"
+	"github.com/mkrou/geonames/geometry"
+	"github.com/mkrou/geonames/models"
+	"iter"
+	"math"
+	"sort"
+)
+
+// Filter selects the geonames that can be found.
+type Filter func(*models.Geoname) bool
+
+// Class finds the geonames of one of the feature classes only.
+func Class(classes ...featureclass.Class) Filter {
+	return func(g *models.Geoname) bool {
+		for _, c := range classes {
+			if g.FeatureClass() == c {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MinPopulation finds the geonames with at least n inhabitants only.
+func MinPopulation(n int) Filter {
+	return func(g *models.Geoname) bool {
+		return g.Population >= n
+	}
+}
+
+// Country finds the geonames in one of the countries with the ISO-3166 2-letter codes only.
+func Country(codes ...string) Filter {
+	return func(g *models.Geoname) bool {
+		for _, c := range codes {
+			if g.CountryCode == c {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Result is a geoname found near a coordinate.
+type Result struct {
+	Geoname  *models.Geoname
+	Distance float64 // great-circle distance in kilometers
+}
+
+type node struct {
+	p [3]float64
+	g *models.Geoname
+}
+
+// Index is a k-d tree of the geonames on the unit sphere, so the distances are right
+// near the poles and across the antimeridian too. It is immutable and safe for concurrent use.
+type Index struct {
+	nodes []node
+}
+
+// Builder collects the geonames of an index. Its Add method can be passed
+// to the Get* methods of a parser:
+//
+//	b := &spatial.Builder{}
+//	err := p.GetGeonames(geonames.Cities500, b.Add)
+//	index := b.Build()
+type Builder struct {
+	nodes []node
+}
+
+// Add adds the geoname to the index, the geoname is kept and must not be modified.
+// It always returns nil.
+func (b *Builder) Add(g *models.Geoname) error {
+	b.nodes = append(b.nodes, node{p: unitVector(g.Latitude, g.Longitude), g: g})
+	return nil
+}
+
+// Build returns the index of the added geonames. The builder must not be used afterwards.
+func (b *Builder) Build() *Index {
+	nodes := b.nodes
+	b.nodes = nil
+	build(nodes, 0)
+	return &Index{nodes: nodes}
+}
+
+// NewIndex returns the index of the geonames of the sequence, e.g. of a gazetteer.
+func NewIndex(geonames iter.Seq[*models.Geoname]) *Index {
+	b := &Builder{}
+	for g := range geonames {
+		b.Add(g)
+	}
+	return b.Build()
+}
+
+// Len returns the number of geonames in the index.
+func (ix *Index) Len() int {
+	return len(ix.nodes)
+}
+
+// Nearest returns up to k geonames that pass all the filters, the nearest first.
+func (ix *Index) Nearest(lat, lon float64, k int, filters ...Filter) []Result {
+	if k <= 0 {
+		return nil
+	}
+
+	s := &search{q: unitVector(lat, lon), k: k, filters: filters}
+	s.visit(ix.nodes, 0)
+
+	sort.Slice(s.found, func(i, j int) bool {
+		return s.found[i].d < s.found[j].d
+	})
+	results := make([]Result, len(s.found))
+	for i, f := range s.found {
+		results[i] = Result{Geoname: f.g, Distance: chordToDistance(f.d)}
+	}
+	return results
+}
+
+// build sorts the nodes into a k-d tree: the median by the axis of the depth is in the middle,
+// the nodes before it are the left subtree and the nodes after it the right one.
+func build(nodes []node, depth int) {
+	if len(nodes) <= 1 {
+		return
+	}
+	axis := depth % 3
+	m := len(nodes) / 2
+	selectNth(nodes, m, axis)
+	build(nodes[:m], depth+1)
+	build(nodes[m+1:], depth+1)
+}
+
+// selectNth moves the nth smallest node by the axis to n, with the smaller ones before it
+// and the bigger ones after it.
+func selectNth(nodes []node, n, axis int) {
+	lo, hi := 0, len(nodes)-1
+	for lo < hi {
+		pivot := nodes[(lo+hi)/2].p[axis]
+		i, j := lo, hi
+		for i <= j {
+			for nodes[i].p[axis] < pivot {
+				i++
+			}
+			for nodes[j].p[axis] > pivot {
+				j--
+			}
+			if i <= j {
+				nodes[i], nodes[j] = nodes[j], nodes[i]
+				i++
+				j--
+			}
+		}
+		switch {
+		case n <= j:
+			hi = j
+		case n >= i:
+			lo = i
+		default:
+			return
+		}
+	}
+}
+
+type candidate struct {
+	d float64 // squared chord distance
+	g *models.Geoname
+}
+
+// candidates is a max-heap of the nearest nodes found so far.
+type candidates []candidate
+
+func (c candidates) Len() int           { return len(c) }
+func (c candidates) Less(i, j int) bool { return c[i].d > c[j].d }
+func (c candidates) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+func (c *candidates) Push(x any)        { *c = append(*c, x.(candidate)) }
+func (c *candidates) Pop() any {
+	old := *c
+	x := old[len(old)-1]
+	*c = old[:len(old)-1]
+	return x
+}
+
+type search struct {
+	q       [3]float64
+	k       int
+	filters []Filter
+	found   candidates
+}
+
+func (s *search) visit(nodes []node, depth int) {
+	if len(nodes) == 0 {
+		return
+	}
+	axis := depth % 3
+	m := len(nodes) / 2
+	n := &nodes[m]
+
+	if accepts(n.g, s.filters) {
+		d := squaredDistance(n.p, s.q)
+		if len(s.found) < s.k {
+			heap.Push(&s.found, candidate{d, n.g})
+		} else if d < s.found[0].d {
+			s.found[0] = candidate{d, n.g}
+			heap.Fix(&s.found, 0)
+		}
+	}
+
+	diff := s.q[axis] - n.p[axis]
+	near, far := nodes[:m], nodes[m+1:]
+	if diff > 0 {
+		near, far = far, near
+	}
+	s.visit(near, depth+1)
+	if len(s.found) < s.k || diff*diff < s.found[0].d {
+		s.visit(far, depth+1)
+	}
+}
+
+func accepts(g *models.Geoname, filters []Filter) bool {
+	for _, f := range filters {
+		if !f(g) {
+			return false
+		}
+	}
+	return true
+}
+
+func unitVector(lat, lon float64) [3]float64 {
+	phi, lambda := radians(lat), radians(lon)
+	return [3]float64{
+		math.Cos(phi) * math.Cos(lambda),
+		math.Cos(phi) * math.Sin(lambda),
+		math.Sin(phi),
+	}
+}
+
+func squaredDistance(a, b [3]float64) float64 {
+	dx, dy, dz := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+	return dx*dx + dy*dy + dz*dz
+}
+
+// chordToDistance returns the great-circle distance in kilometers
+// of the squared chord distance on the unit sphere.
+func chordToDistance(d float64) float64 {
+	return 2 * geometry.EarthRadius * math.Asin(math.Min(1, math.Sqrt(d)/2))
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
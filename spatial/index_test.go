@@ -0,0 +1,111 @@
+package spatial
+
+import (
+	"github.com/mkrou/geonames/featureclass"
+	"github.com/mkrou/geonames/geometry"
+	"github.com/mkrou/geonames/models"
+	. "github.com/smartystreets/goconvey/convey"
+	"math"
+	"math/rand"
+	"slices"
+	"sort"
+	"testing"
+)
+
+func places() []*models.Geoname {
+	return []*models.Geoname{
+		{Id: 1, Name: "Paris", Latitude: 48.85341, Longitude: 2.3488, Class: "P", CountryCode: "FR", Population: 2138551},
+		{Id: 2, Name: "Versailles", Latitude: 48.80359, Longitude: 2.13424, Class: "P", CountryCode: "FR", Population: 85416},
+		{Id: 3, Name: "Bois de Boulogne", Latitude: 48.86254, Longitude: 2.24919, Class: "L", CountryCode: "FR"},
+		{Id: 4, Name: "London", Latitude: 51.50853, Longitude: -0.12574, Class: "P", CountryCode: "GB", Population: 8961989},
+		{Id: 5, Name: "Suva", Latitude: -18.14161, Longitude: 178.44149, Class: "P", CountryCode: "FJ", Population: 77366},
+		{Id: 6, Name: "Apia", Latitude: -13.83333, Longitude: -171.76666, Class: "P", CountryCode: "WS", Population: 40407},
+	}
+}
+
+func names(results []Result) []string {
+	var x []string
+	for _, r := range results {
+		x = append(x, r.Geoname.Name)
+	}
+	return x
+}
+
+func TestNearest(t *testing.T) {
+	Convey("Given an index of a few places", t, func() {
+		b := &Builder{}
+		for _, g := range places() {
+			So(b.Add(g), ShouldBeNil)
+		}
+		ix := b.Build()
+		So(ix.Len(), ShouldEqual, 6)
+
+		Convey("The nearest places should be found first", func() {
+			results := ix.Nearest(48.8566, 2.3522, 3)
+			So(names(results), ShouldResemble, []string{"Paris", "Bois de Boulogne", "Versailles"})
+			So(results[0].Distance, ShouldAlmostEqual, geometry.Haversine(48.8566, 2.3522, 48.85341, 2.3488), 1e-6)
+		})
+
+		Convey("The filters should be applied", func() {
+			So(names(ix.Nearest(48.8566, 2.3522, 2, Class(featureclass.PopulatedPlace))), ShouldResemble, []string{"Paris", "Versailles"})
+			So(names(ix.Nearest(48.8566, 2.3522, 2, MinPopulation(100000))), ShouldResemble, []string{"Paris", "London"})
+			So(names(ix.Nearest(48.8566, 2.3522, 5, Country("GB"))), ShouldResemble, []string{"London"})
+		})
+
+		Convey("The nearest place across the antimeridian should be found", func() {
+			So(names(ix.Nearest(-18, -179.9, 1)), ShouldResemble, []string{"Suva"})
+			So(names(ix.Nearest(-14, 179.9, 1, MinPopulation(50000))), ShouldResemble, []string{"Suva"})
+			So(names(ix.Nearest(-14, -173, 1)), ShouldResemble, []string{"Apia"})
+		})
+
+		Convey("No places should be found for k of 0", func() {
+			So(ix.Nearest(0, 0, 0), ShouldBeEmpty)
+		})
+	})
+
+	Convey("Given an index of random places", t, func() {
+		r := rand.New(rand.NewSource(1))
+		var all []*models.Geoname
+		for i := 0; i < 5000; i++ {
+			all = append(all, &models.Geoname{
+				Id:         i,
+				Latitude:   math.Asin(2*r.Float64()-1) * 180 / math.Pi,
+				Longitude:  360*r.Float64() - 180,
+				Population: r.Intn(1000),
+			})
+		}
+		ix := NewIndex(slices.Values(all))
+
+		Convey("The results should be the same as of a linear search", func() {
+			for i := 0; i < 50; i++ {
+				lat, lon := 180*r.Float64()-90, 360*r.Float64()-180
+				expected := slices.Clone(all)
+				expected = slices.DeleteFunc(expected, func(g *models.Geoname) bool { return g.Population < 500 })
+				sort.Slice(expected, func(i, j int) bool {
+					return geometry.Haversine(lat, lon, expected[i].Latitude, expected[i].Longitude) < geometry.Haversine(lat, lon, expected[j].Latitude, expected[j].Longitude)
+				})
+
+				results := ix.Nearest(lat, lon, 10, MinPopulation(500))
+				So(len(results), ShouldEqual, 10)
+				for j, result := range results {
+					So(result.Geoname.Id, ShouldEqual, expected[j].Id)
+					So(result.Distance, ShouldAlmostEqual, geometry.Haversine(lat, lon, expected[j].Latitude, expected[j].Longitude), 1e-6)
+				}
+			}
+		})
+	})
+}
+
+func BenchmarkNearest(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	builder := &Builder{}
+	for i := 0; i < 1000000; i++ {
+		builder.Add(&models.Geoname{Id: i, Latitude: 180*r.Float64() - 90, Longitude: 360*r.Float64() - 180})
+	}
+	ix := builder.Build()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ix.Nearest(180*r.Float64()-90, 360*r.Float64()-180, 10)
+	}
+}
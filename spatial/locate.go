@@ -0,0 +1,156 @@
+package spatial
+
+import (
+	"context"
+	"github.com/mkrou/geonames"
+	"github.com/mkrou/geonames/geometry"
+	"github.com/mkrou/geonames/models"
+	"math"
+)
+
+// kmPerDegree is the length of a degree of latitude in kilometers.
+const kmPerDegree = geometry.EarthRadius * math.Pi / 180
+
+// Locator finds the country that contains a coordinate by the shapes of the countries.
+// It is immutable and safe for concurrent use.
+type Locator struct {
+	polygons  []geometry.Polygon
+	countries []*models.Country // country of the polygon at the same index
+	tree      *rtree
+	tolerance float64
+}
+
+// NewLocator returns a locator of the countries by their shapes, which are matched by the geonameid.
+// A coordinate outside of all the shapes, e.g. on a coast simplified away, is located
+// in the nearest country within tolerance kilometers.
+func NewLocator(countries []*models.Country, shapes []*models.Shape, tolerance float64) (*Locator, error) {
+	byId := map[int]*models.Country{}
+	for _, c := range countries {
+		byId[c.GeonameID] = c
+	}
+
+	l := &Locator{tolerance: tolerance}
+	var boxes []geometry.BBox
+	for _, s := range shapes {
+		c, ok := byId[s.GeonameId]
+		if !ok {
+			continue
+		}
+		g, err := s.Geometry()
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range g {
+			if len(p) == 0 || len(p[0]) == 0 {
+				continue
+			}
+			b := p.BBox()
+			halves := []geometry.BBox{b}
+			if b.CrossesAntimeridian() {
+				// the tree compares plain boxes, so a box across the antimeridian is added as its two halves
+				east, west := b, b
+				east.MaxLon, west.MinLon = 180, -180
+				halves = []geometry.BBox{east, west}
+			}
+			for _, b := range halves {
+				l.polygons = append(l.polygons, p)
+				l.countries = append(l.countries, c)
+				boxes = append(boxes, b)
+			}
+		}
+	}
+	l.tree = newRTree(boxes)
+	return l, nil
+}
+
+// LoadLocator reads the countries and their shapes with p. See NewLocator.
+func LoadLocator(ctx context.Context, p geonames.Parser, tolerance float64) (*Locator, error) {
+	var countries []*models.Country
+	err := p.GetCountriesContext(ctx, func(c *models.Country) error {
+		countries = append(countries, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var shapes []*models.Shape
+	err = p.GetShapesContext(ctx, func(s *models.Shape) error {
+		shapes = append(shapes, s)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewLocator(countries, shapes, tolerance)
+}
+
+// Locate returns the country that contains the coordinate
+// or the nearest one within the tolerance.
+func (l *Locator) Locate(lat, lon float64) (*models.Country, bool) {
+	var found *models.Country
+	point := geometry.BBox{MinLon: lon, MinLat: lat, MaxLon: lon, MaxLat: lat}
+	l.tree.search(point, func(i int) bool {
+		if l.polygons[i].Contains(lat, lon) {
+			found = l.countries[i]
+			return false
+		}
+		return true
+	})
+	if found != nil || l.tolerance <= 0 {
+		return found, found != nil
+	}
+
+	nearest := l.tolerance
+	dLat := l.tolerance / kmPerDegree
+	dLon := 180.0
+	if cos := math.Cos(radians(lat)); cos > dLat/180 {
+		dLon = math.Min(180, dLat/cos)
+	}
+	around := geometry.BBox{MinLon: lon - dLon, MinLat: lat - dLat, MaxLon: lon + dLon, MaxLat: lat + dLat}
+	// the part of the box beyond the antimeridian is searched on the other side too
+	for _, shift := range []float64{0, -360, 360} {
+		b := geometry.BBox{MinLon: around.MinLon + shift, MinLat: around.MinLat, MaxLon: around.MaxLon + shift, MaxLat: around.MaxLat}
+		if b.MaxLon < -180 || b.MinLon > 180 {
+			continue
+		}
+		l.tree.search(b, func(i int) bool {
+			if d := distanceToPolygon(lat, lon, l.polygons[i]); d <= nearest {
+				nearest, found = d, l.countries[i]
+			}
+			return true
+		})
+	}
+	return found, found != nil
+}
+
+// distanceToPolygon returns the distance in kilometers from the coordinate to the nearest edge of the polygon
+// on a plane tangent at the coordinate, which is accurate for the short distances of the tolerance.
+func distanceToPolygon(lat, lon float64, p geometry.Polygon) float64 {
+	scale := math.Cos(radians(lat))
+	project := func(q geometry.Point) (float64, float64) {
+		dLon := math.Mod(q.Lon-lon+540, 360) - 180
+		return dLon * scale * kmPerDegree, (q.Lat - lat) * kmPerDegree
+	}
+
+	nearest := math.Inf(1)
+	for _, r := range p {
+		for i := 0; i+1 < len(r); i++ {
+			ax, ay := project(r[i])
+			bx, by := project(r[i+1])
+			nearest = math.Min(nearest, distanceToSegment(ax, ay, bx, by))
+		}
+	}
+	return nearest
+}
+
+// distanceToSegment returns the distance from the origin to the segment from a to b.
+func distanceToSegment(ax, ay, bx, by float64) float64 {
+	dx, dy := bx-ax, by-ay
+	t := 0.0
+	if l := dx*dx + dy*dy; l > 0 {
+		t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/l))
+	}
+	return math.Hypot(ax+t*dx, ay+t*dy)
+}
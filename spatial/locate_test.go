@@ -0,0 +1,117 @@
+package spatial
+
+import (
+	"fmt"
+	"github.com/mkrou/geonames/models"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func square(id int, minLon, minLat, maxLon, maxLat float64) *models.Shape {
+	return &models.Shape{GeonameId: id, GeoJson: fmt.Sprintf(`{"type":"Polygon","coordinates":[[[%[1]v,%[2]v],[%[3]v,%[2]v],[%[3]v,%[4]v],[%[1]v,%[4]v],[%[1]v,%[2]v]]]}`, minLon, minLat, maxLon, maxLat)}
+}
+
+func TestLocate(t *testing.T) {
+	Convey("Given the shapes of a few countries", t, func() {
+		countries := []*models.Country{
+			{Iso2Code: "AA", GeonameID: 1},
+			{Iso2Code: "BB", GeonameID: 2},
+			{Iso2Code: "FJ", GeonameID: 3},
+			{Iso2Code: "RU", GeonameID: 4},
+		}
+		shapes := []*models.Shape{
+			square(1, 0, 0, 10, 10),
+			square(2, 10, 0, 20, 10),
+			{GeonameId: 3, GeoJson: `{"type":"MultiPolygon","coordinates":[[[[177,-19],[180,-19],[180,-16],[177,-16],[177,-19]]],[[[-180,-17],[-179,-17],[-179,-16],[-180,-16],[-180,-17]]]]}`},
+			square(4, 175, 65, -175, 70), // across the antimeridian
+			square(99, 50, 50, 60, 60),   // no country
+		}
+		// many small shapes around, so the tree has several levels
+		for i := 0; i < 500; i++ {
+			countries = append(countries, &models.Country{Iso2Code: fmt.Sprintf("%03d", i), GeonameID: 1000 + i})
+			lon, lat := float64(i%50)*0.5-100, float64(i/50)*0.5-40
+			shapes = append(shapes, square(1000+i, lon, lat, lon+0.4, lat+0.4))
+		}
+
+		l, err := NewLocator(countries, shapes, 50)
+		So(err, ShouldBeNil)
+
+		Convey("The country containing the coordinate should be found", func() {
+			c, ok := l.Locate(5, 5)
+			So(ok, ShouldBeTrue)
+			So(c.Iso2Code, ShouldEqual, "AA")
+
+			c, ok = l.Locate(5, 15)
+			So(ok, ShouldBeTrue)
+			So(c.Iso2Code, ShouldEqual, "BB")
+
+			c, ok = l.Locate(-40+0.5*3+0.2, -100+0.5*7+0.2)
+			So(ok, ShouldBeTrue)
+			So(c.Iso2Code, ShouldEqual, "157") // the square of the 7th column in the 3rd row
+		})
+
+		Convey("Every part of a multipolygon should be searched", func() {
+			c, ok := l.Locate(-16.5, -179.5)
+			So(ok, ShouldBeTrue)
+			So(c.Iso2Code, ShouldEqual, "FJ")
+		})
+
+		Convey("A polygon across the antimeridian should contain both sides", func() {
+			c, ok := l.Locate(67, 178)
+			So(ok, ShouldBeTrue)
+			So(c.Iso2Code, ShouldEqual, "RU")
+
+			c, ok = l.Locate(67, -178)
+			So(ok, ShouldBeTrue)
+			So(c.Iso2Code, ShouldEqual, "RU")
+
+			_, ok = l.Locate(67, 0)
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("The nearest country within the tolerance should be found", func() {
+			c, ok := l.Locate(10.2, 5)
+			So(ok, ShouldBeTrue)
+			So(c.Iso2Code, ShouldEqual, "AA")
+
+			c, ok = l.Locate(-18, -179.8)
+			So(ok, ShouldBeTrue)
+			So(c.Iso2Code, ShouldEqual, "FJ")
+		})
+
+		Convey("No country should be found beyond the tolerance", func() {
+			_, ok := l.Locate(11, 5)
+			So(ok, ShouldBeFalse)
+
+			_, ok = l.Locate(55, 55)
+			So(ok, ShouldBeFalse)
+		})
+	})
+
+	Convey("Given an invalid shape", t, func() {
+		_, err := NewLocator([]*models.Country{{GeonameID: 1}}, []*models.Shape{{GeonameId: 1, GeoJson: "{"}}, 0)
+
+		Convey("The error should be returned", func() {
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func BenchmarkLocate(b *testing.B) {
+	var countries []*models.Country
+	var shapes []*models.Shape
+	for i := 0; i < 10000; i++ {
+		countries = append(countries, &models.Country{GeonameID: i})
+		lon, lat := float64(i%100)*3.6-180, float64(i/100)*1.8-90
+		shapes = append(shapes, square(i, lon, lat, lon+3, lat+1.5))
+	}
+	l, err := NewLocator(countries, shapes, 20)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Locate(float64(i%180)-89.5, float64(i%360)-179.5)
+	}
+}
@@ -0,0 +1,131 @@
+package spatial
+
+import (
+	"github.com/mkrou/geonames/geometry"
+	"github.com/mkrou/geonames/models"
+	"math"
+	"sort"
+)
+
+// WithinRadius returns the geonames that pass all the filters within radius kilometers
+// of the coordinate, the nearest first.
+func (ix *Index) WithinRadius(lat, lon, radius float64, filters ...Filter) []Result {
+	if radius < 0 {
+		return nil
+	}
+
+	q := unitVector(lat, lon)
+	chord := 2 * math.Sin(math.Min(radius/geometry.EarthRadius, math.Pi)/2)
+	max := chord * chord
+	var lo, hi [3]float64
+	for i := range q {
+		lo[i], hi[i] = q[i]-chord, q[i]+chord
+	}
+
+	var results []Result
+	r := &rangeSearch{lo: lo, hi: hi, filters: filters, found: func(n *node) {
+		if d := squaredDistance(n.p, q); d <= max {
+			results = append(results, Result{Geoname: n.g, Distance: chordToDistance(d)})
+		}
+	}}
+	r.visit(ix.nodes, 0)
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Distance < results[j].Distance
+	})
+	return results
+}
+
+// WithinBBox returns the geonames that pass all the filters within the box, in no particular order.
+// A box with a MinLon greater than the MaxLon spans the antimeridian.
+func (ix *Index) WithinBBox(b geometry.BBox, filters ...Filter) []*models.Geoname {
+	lo, hi := bounds(b)
+
+	var results []*models.Geoname
+	r := &rangeSearch{lo: lo, hi: hi, filters: filters, found: func(n *node) {
+		if b.Contains(n.g.Latitude, n.g.Longitude) {
+			results = append(results, n.g)
+		}
+	}}
+	r.visit(ix.nodes, 0)
+	return results
+}
+
+// rangeSearch visits the nodes in an axis-aligned box of the unit vectors.
+type rangeSearch struct {
+	lo, hi  [3]float64
+	filters []Filter
+	found   func(*node)
+}
+
+func (r *rangeSearch) visit(nodes []node, depth int) {
+	if len(nodes) == 0 {
+		return
+	}
+	axis := depth % 3
+	m := len(nodes) / 2
+	n := &nodes[m]
+
+	if r.contains(n.p) && accepts(n.g, r.filters) {
+		r.found(n)
+	}
+	if r.lo[axis] <= n.p[axis] {
+		r.visit(nodes[:m], depth+1)
+	}
+	if r.hi[axis] >= n.p[axis] {
+		r.visit(nodes[m+1:], depth+1)
+	}
+}
+
+func (r *rangeSearch) contains(p [3]float64) bool {
+	for i := range p {
+		if p[i] < r.lo[i] || p[i] > r.hi[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// bounds returns the axis-aligned box of the unit vectors of the coordinates in b.
+// The vectors are cos(lat)*cos(lon), cos(lat)*sin(lon) and sin(lat),
+// so their bounds are the products of the bounds of the factors.
+func bounds(b geometry.BBox) (lo, hi [3]float64) {
+	minLat, maxLat := radians(math.Max(b.MinLat, -90)), radians(math.Min(b.MaxLat, 90))
+
+	cosLat := interval{math.Min(math.Cos(minLat), math.Cos(maxLat)), math.Max(math.Cos(minLat), math.Cos(maxLat))}
+	if minLat <= 0 && maxLat >= 0 {
+		cosLat.hi = 1
+	}
+
+	cosLon, sinLon := interval{math.Inf(1), math.Inf(-1)}, interval{math.Inf(1), math.Inf(-1)}
+	for _, lon := range []float64{b.MinLon, b.MaxLon, -180, -90, 0, 90, 180} {
+		if b.Contains(b.MinLat, lon) {
+			cosLon = cosLon.extend(math.Cos(radians(lon)))
+			sinLon = sinLon.extend(math.Sin(radians(lon)))
+		}
+	}
+
+	// the margin keeps the coordinates on the border despite rounding, they are checked exactly afterwards
+	const margin = 1e-9
+	x, y := cosLat.times(cosLon), cosLat.times(sinLon)
+	lo = [3]float64{x.lo - margin, y.lo - margin, math.Sin(minLat) - margin}
+	hi = [3]float64{x.hi + margin, y.hi + margin, math.Sin(maxLat) + margin}
+	return lo, hi
+}
+
+type interval struct {
+	lo, hi float64
+}
+
+func (i interval) extend(v float64) interval {
+	return interval{math.Min(i.lo, v), math.Max(i.hi, v)}
+}
+
+func (i interval) times(j interval) interval {
+	products := []float64{i.lo * j.lo, i.lo * j.hi, i.hi * j.lo, i.hi * j.hi}
+	r := interval{math.Inf(1), math.Inf(-1)}
+	for _, p := range products {
+		r = r.extend(p)
+	}
+	return r
+}
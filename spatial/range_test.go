@@ -0,0 +1,107 @@
+package spatial
+
+import (
+	"github.com/mkrou/geonames/featureclass"
+	"github.com/mkrou/geonames/geometry"
+	"github.com/mkrou/geonames/models"
+	. "github.com/smartystreets/goconvey/convey"
+	"math"
+	"math/rand"
+	"slices"
+	"sort"
+	"testing"
+)
+
+func ids(geonames []*models.Geoname) []int {
+	var x []int
+	for _, g := range geonames {
+		x = append(x, g.Id)
+	}
+	sort.Ints(x)
+	return x
+}
+
+func TestWithinRadius(t *testing.T) {
+	Convey("Given an index of a few places", t, func() {
+		ix := NewIndex(slices.Values(places()))
+
+		Convey("The places within the radius should be found, the nearest first", func() {
+			results := ix.WithinRadius(48.8566, 2.3522, 50)
+			So(names(results), ShouldResemble, []string{"Paris", "Bois de Boulogne", "Versailles"})
+			So(results[2].Distance, ShouldBeLessThanOrEqualTo, 50)
+		})
+
+		Convey("The filters should be applied", func() {
+			results := ix.WithinRadius(48.8566, 2.3522, 50, Class(featureclass.PopulatedPlace), MinPopulation(100000))
+			So(names(results), ShouldResemble, []string{"Paris"})
+		})
+
+		Convey("The places across the antimeridian should be found", func() {
+			So(names(ix.WithinRadius(-18, -179.9, 200)), ShouldResemble, []string{"Suva"})
+		})
+
+		Convey("A radius around the earth should find every place", func() {
+			So(len(ix.WithinRadius(0, 0, 30000)), ShouldEqual, 6)
+			So(ix.WithinRadius(0, 0, -1), ShouldBeEmpty)
+		})
+	})
+}
+
+func TestWithinBBox(t *testing.T) {
+	Convey("Given an index of a few places", t, func() {
+		ix := NewIndex(slices.Values(places()))
+
+		Convey("The places in the box should be found", func() {
+			x := ix.WithinBBox(geometry.BBox{MinLon: 2, MinLat: 48, MaxLon: 3, MaxLat: 49})
+			So(ids(x), ShouldResemble, []int{1, 2, 3})
+		})
+
+		Convey("The places in a box across the antimeridian should be found", func() {
+			x := ix.WithinBBox(geometry.BBox{MinLon: 170, MinLat: -20, MaxLon: -170, MaxLat: -10})
+			So(ids(x), ShouldResemble, []int{5, 6})
+		})
+	})
+
+	Convey("Given an index of random places", t, func() {
+		r := rand.New(rand.NewSource(2))
+		var all []*models.Geoname
+		for i := 0; i < 5000; i++ {
+			all = append(all, &models.Geoname{Id: i, Latitude: math.Asin(2*r.Float64()-1) * 180 / math.Pi, Longitude: 360*r.Float64() - 180})
+		}
+		ix := NewIndex(slices.Values(all))
+
+		Convey("The results should be the same as of a linear search", func() {
+			boxes := []geometry.BBox{
+				{MinLon: -10, MinLat: 30, MaxLon: 40, MaxLat: 60},
+				{MinLon: 150, MinLat: -50, MaxLon: -160, MaxLat: 10},
+				{MinLon: -180, MinLat: 75, MaxLon: 180, MaxLat: 90},
+				{MinLon: 100, MinLat: -90, MaxLon: 120, MaxLat: -70},
+				{MinLon: 80, MinLat: -5, MaxLon: 80.5, MaxLat: 5},
+			}
+			for _, b := range boxes {
+				var expected []*models.Geoname
+				for _, g := range all {
+					if b.Contains(g.Latitude, g.Longitude) {
+						expected = append(expected, g)
+					}
+				}
+				So(ids(ix.WithinBBox(b)), ShouldResemble, ids(expected))
+			}
+
+			for i := 0; i < 20; i++ {
+				lat, lon, radius := 180*r.Float64()-90, 360*r.Float64()-180, 3000*r.Float64()
+				var expected []*models.Geoname
+				for _, g := range all {
+					if geometry.Haversine(lat, lon, g.Latitude, g.Longitude) <= radius {
+						expected = append(expected, g)
+					}
+				}
+				var found []*models.Geoname
+				for _, result := range ix.WithinRadius(lat, lon, radius) {
+					found = append(found, result.Geoname)
+				}
+				So(ids(found), ShouldResemble, ids(expected))
+			}
+		})
+	})
+}
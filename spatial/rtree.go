@@ -0,0 +1,102 @@
+package spatial
+
+import (
+	"github.com/mkrou/geonames/geometry"
+	"math"
+	"sort"
+)
+
+// nodeCapacity is the number of children of a node of the r-tree.
+const nodeCapacity = 16
+
+// rtree is a static r-tree of boxes packed with the Sort-Tile-Recursive algorithm.
+// The boxes must not span the antimeridian.
+type rtree struct {
+	root *rnode
+}
+
+type rnode struct {
+	box      geometry.BBox
+	children []*rnode
+	entry    int // index of the box of a leaf, -1 for inner nodes
+}
+
+func newRTree(boxes []geometry.BBox) *rtree {
+	if len(boxes) == 0 {
+		return &rtree{}
+	}
+
+	level := make([]*rnode, len(boxes))
+	for i, b := range boxes {
+		level[i] = &rnode{box: b, entry: i}
+	}
+	for len(level) > 1 {
+		level = pack(level)
+	}
+	return &rtree{root: level[0]}
+}
+
+// pack groups the nodes into parents of up to nodeCapacity children:
+// the nodes are sorted into vertical slices by longitude and each slice into groups by latitude.
+func pack(nodes []*rnode) []*rnode {
+	parents := int(math.Ceil(float64(len(nodes)) / nodeCapacity))
+	slices := int(math.Ceil(math.Sqrt(float64(parents))))
+	sliceSize := slices * nodeCapacity
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].box.MinLon+nodes[i].box.MaxLon < nodes[j].box.MinLon+nodes[j].box.MaxLon
+	})
+
+	var level []*rnode
+	for start := 0; start < len(nodes); start += sliceSize {
+		slice := nodes[start:min(start+sliceSize, len(nodes))]
+		sort.Slice(slice, func(i, j int) bool {
+			return slice[i].box.MinLat+slice[i].box.MaxLat < slice[j].box.MinLat+slice[j].box.MaxLat
+		})
+
+		for g := 0; g < len(slice); g += nodeCapacity {
+			children := slice[g:min(g+nodeCapacity, len(slice))]
+			parent := &rnode{box: children[0].box, children: children, entry: -1}
+			for _, c := range children[1:] {
+				parent.box = union(parent.box, c.box)
+			}
+			level = append(level, parent)
+		}
+	}
+	return level
+}
+
+// search calls fn with the index of every box that overlaps b until fn returns false.
+func (t *rtree) search(b geometry.BBox, fn func(i int) bool) {
+	if t.root != nil {
+		t.root.search(b, fn)
+	}
+}
+
+func (n *rnode) search(b geometry.BBox, fn func(i int) bool) bool {
+	if !overlaps(n.box, b) {
+		return true
+	}
+	if n.entry >= 0 {
+		return fn(n.entry)
+	}
+	for _, c := range n.children {
+		if !c.search(b, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+func union(a, b geometry.BBox) geometry.BBox {
+	return geometry.BBox{
+		MinLon: math.Min(a.MinLon, b.MinLon),
+		MinLat: math.Min(a.MinLat, b.MinLat),
+		MaxLon: math.Max(a.MaxLon, b.MaxLon),
+		MaxLat: math.Max(a.MaxLat, b.MaxLat),
+	}
+}
+
+func overlaps(a, b geometry.BBox) bool {
+	return a.MinLon <= b.MaxLon && b.MinLon <= a.MaxLon && a.MinLat <= b.MaxLat && b.MinLat <= a.MaxLat
+}
@@ -0,0 +1,169 @@
+package stream
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jszwec/csvutil"
+	"github.com/mkrou/geonames/csv"
+	"reflect"
+	"strings"
+)
+
+// RowError describes a record that could not be decoded or was rejected by the handler.
+// Use errors.As to get it from the error of a parsing.
+type RowError struct {
+	File   string // dump file set with Source, e.g. "cities500.zip"
+	Entry  string // file in the archive, e.g. "cities500.txt"
+	Line   int    // line where the record starts, as in csv.ParseError
+	Column string // header of the column that could not be decoded
+	Value  string // raw value of that column
+	Record string // raw record with the fields joined by tabs
+	Model  string // type the record was decoded into, e.g. "models.Geoname"
+	Err    error
+}
+
+func (e *RowError) Error() string {
+	b := &strings.Builder{}
+	for _, name := range []string{e.File, e.Entry} {
+		if name != "" {
+			b.WriteString(name)
+			b.WriteString(": ")
+		}
+	}
+	fmt.Fprintf(b, "line %d", e.Line)
+	if e.Column != "" {
+		fmt.Fprintf(b, ", column %q", e.Column)
+	}
+	fmt.Fprintf(b, ": %v", e.Err)
+	return b.String()
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// Report summarizes a parsing with SkipInvalid.
+// The parsing functions return only an error, so the counts are available
+// only through the Report passed to SkipInvalid.
+type Report struct {
+	Records int         // records passed to the handler
+	Skipped int         // records that could not be decoded
+	Errors  []*RowError // errors of the skipped records
+}
+
+// SkipInvalid skips the records that can not be decoded instead of returning the error.
+// The passed and skipped records are counted in report, which is the only way to get
+// the counts after the parsing. With a nil report they are skipped silently.
+// A record with more or fewer fields than the header is reported with a *csv.ParseError.
+func SkipInvalid(report *Report) Option {
+	return func(c *config) {
+		if report == nil {
+			report = &Report{}
+		}
+		c.report = report
+		c.onInvalid = nil
+	}
+}
+
+// OnInvalid calls f for every record that can not be decoded. The record is skipped
+// if f returns nil, otherwise the parsing stops with the returned error.
+func OnInvalid(f func(*RowError) error) Option {
+	return func(c *config) {
+		c.onInvalid = f
+		c.report = nil
+	}
+}
+
+// FailFast stops the parsing at the first record that can not be decoded
+// and returns its *RowError. It is the default.
+func FailFast() Option {
+	return func(c *config) {
+		c.onInvalid = nil
+		c.report = nil
+	}
+}
+
+// invalid applies the error policy to a record that could not be decoded.
+func (c *config) invalid(e *RowError) error {
+	switch {
+	case c.onInvalid != nil:
+		return c.onInvalid(e)
+	case c.report != nil:
+		c.report.Skipped++
+		c.report.Errors = append(c.report.Errors, e)
+		return nil
+	default:
+		return e
+	}
+}
+
+// delivered counts a record passed to the handler.
+func (c *config) delivered() {
+	if c.report != nil {
+		c.report.Records++
+	}
+}
+
+func (c *config) rowError(line int, record []string, model reflect.Type, err error) *RowError {
+	var perr *csv.ParseError
+	if errors.As(err, &perr) {
+		line = perr.StartLine
+	}
+
+	return &RowError{
+		File:   c.source,
+		Entry:  c.entry,
+		Line:   line,
+		Record: strings.Join(record, "\t"),
+		Model:  model.String(),
+		Err:    err,
+	}
+}
+
+// column remembers the column a decoder is decoding,
+// so a failure can be attributed to it.
+type column struct {
+	name  string
+	value string
+}
+
+// watch makes dec record every column it decodes in c.
+func (c *column) watch(dec *csvutil.Decoder) {
+	dec.Map = func(field, col string, v interface{}) string {
+		c.name, c.value = col, field
+		return field
+	}
+}
+
+// decodeError returns the error of a record that could not be decoded.
+func (c *config) decodeError(line int, record []string, model reflect.Type, col *column, err error) *RowError {
+	e := c.rowError(line, record, model, err)
+	if !isRowError(err) {
+		e.Column, e.Value = col.name, col.value
+	}
+	return e
+}
+
+// recordSource reads the records for a decoder and remembers where the last one starts
+// and whether reading it failed for another reason than a malformed record.
+type recordSource struct {
+	r       *csv.Reader
+	line    int
+	readErr error
+}
+
+func (s *recordSource) Read() ([]string, error) {
+	record, err := s.r.Read()
+	s.line = s.r.Line()
+	s.readErr = nil
+	if err != nil && !isRowError(err) {
+		s.readErr = err
+	}
+	return record, err
+}
+
+// isRowError reports whether reading can continue after err with the next record.
+func isRowError(err error) bool {
+	var perr *csv.ParseError
+	return errors.As(err, &perr)
+}
@@ -0,0 +1,76 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ArchiveFeatures decodes every feature of the GeoJSON FeatureCollection with the given name
+// in the zip archive into a new T and passes it to handler. See Features.
+func ArchiveFeatures[T any](ctx context.Context, r io.Reader, filename string, handler func(*T) error) error {
+	return streamArchive(r, filename, func(entry io.Reader) error {
+		return Features(ctx, entry, handler)
+	})
+}
+
+// Features decodes every feature of a GeoJSON FeatureCollection into a new T
+// with encoding/json and passes it to handler until the end of the collection,
+// the first error or ctx is done. Only one feature is kept in memory at a time.
+// The error of the handler is returned as is.
+func Features[T any](ctx context.Context, r io.Reader, handler func(*T) error) error {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		if key != "features" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := expectDelim(dec, '['); err != nil {
+			return err
+		}
+		for dec.More() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			v := new(T)
+			if err := dec.Decode(v); err != nil {
+				return err
+			}
+			if err := handler(v); err != nil {
+				return err
+			}
+		}
+		return expectDelim(dec, ']')
+	}
+
+	return fmt.Errorf("FeatureCollection has no features")
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	t, err := dec.Token()
+	if err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	if t != delim {
+		return fmt.Errorf("FeatureCollection: expected %v at offset %d, got %v", delim, dec.InputOffset(), t)
+	}
+	return nil
+}
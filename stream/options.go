@@ -0,0 +1,83 @@
+package stream
+
+import "github.com/jszwec/csvutil"
+
+// Option configures how File and Archive decode the records.
+type Option func(*config)
+
+// Workers parses and decodes the records on n goroutines while the lines are read on the calling one.
+// The handler is still called on the calling goroutine, in the order of the file
+// unless Unordered is given too. Values below 2 parse and decode the records on the calling goroutine.
+func Workers(n int) Option {
+	return func(c *config) {
+		c.workers = n
+	}
+}
+
+// Unordered passes the batches of records to the handler in the order the workers finish them
+// instead of in the order of the file. The handler is still called on the calling goroutine only,
+// one record at a time, so it needs no locking.
+func Unordered() Option {
+	return func(c *config) {
+		c.unordered = true
+	}
+}
+
+// Source names the parsed file in the errors of the records.
+func Source(file string) Option {
+	return func(c *config) {
+		c.source = file
+	}
+}
+
+// SkipColumns leaves the fields of the columns with the given names empty
+// instead of decoding them, e.g. SkipColumns("alternatenames") for the long lists of names.
+func SkipColumns(names ...string) Option {
+	return func(c *config) {
+		c.skip = append(c.skip, names...)
+	}
+}
+
+type config struct {
+	source    string
+	entry     string
+	workers   int
+	unordered bool
+	skip      []string
+	report    *Report
+	onInvalid func(*RowError) error
+}
+
+func newConfig(options []Option) *config {
+	c := &config{}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// header returns the names of the columns, read from the first line of the file if missedHeaders is empty.
+// The skipped columns are renamed to "", so no field is decoded from them.
+func (c *config) header(r csvutil.Reader, missedHeaders []string) ([]string, error) {
+	header := missedHeaders
+	if len(header) == 0 {
+		record, err := r.Read()
+		if err != nil {
+			return nil, err
+		}
+		header = record
+	}
+	if len(c.skip) == 0 {
+		return header, nil
+	}
+
+	header = append([]string(nil), header...)
+	for i, name := range header {
+		for _, skip := range c.skip {
+			if name == skip {
+				header[i] = ""
+			}
+		}
+	}
+	return header, nil
+}
@@ -0,0 +1,229 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"github.com/jszwec/csvutil"
+	"github.com/mkrou/geonames/csv"
+	"io"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// batchSize is the number of lines a worker parses and decodes at once.
+const batchSize = 512
+
+type batch[T any] struct {
+	seq     int
+	data    []byte // whole lines of the file
+	line    int    // line number of the first line of data
+	records [][]string
+	lines   []int
+	values  []*T
+	invalid []*RowError // error of the record at the same index, values[i] is nil then
+	err     error
+}
+
+// parallel splits the file into batches of lines on the calling goroutine, parses and decodes
+// the batches on c.workers goroutines and passes the records to the handler on the calling goroutine.
+// A record is a single line, since the fields of the dump are never quoted.
+// All goroutines have finished when it returns, so the reader is not used afterwards.
+func parallel[T any](ctx context.Context, reader io.Reader, missedHeaders []string, handler func(*T) error, c *config) error {
+	br := bufio.NewReader(reader)
+	// csv.NewReader reads from br itself, so the lines after the header are left in br
+	r := newReader(br)
+	headers, err := c.header(r, missedHeaders)
+	if err != nil {
+		return err
+	}
+	line := 1
+	if len(missedHeaders) == 0 {
+		line = r.Line() + 1
+	}
+	model := reflect.TypeOf((*T)(nil)).Elem()
+
+	inflight := 2 * c.workers
+	jobs := make(chan *batch[T], inflight)
+	results := make(chan *batch[T], inflight)
+	var stopped atomic.Bool
+	var wg sync.WaitGroup
+	for i := 0; i < c.workers; i++ {
+		records := &recordReader{}
+		dec, err := csvutil.NewDecoder(records, headers...)
+		if err != nil {
+			close(jobs)
+			wg.Wait()
+			return err
+		}
+		col := &column{}
+		col.watch(dec)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range jobs {
+				b.parse(c, model, len(headers))
+				records.records = b.records
+				b.decode(dec, c, model, col, &stopped)
+				results <- b
+			}
+		}()
+	}
+
+	pending := map[int]*batch[T]{}
+	next, seq, running, eof := 0, 0, 0, false
+
+	deliver := func(b *batch[T]) {
+		for i, v := range b.values {
+			if err = ctx.Err(); err != nil {
+				return
+			}
+			if v == nil {
+				if err = c.invalid(b.invalid[i]); err != nil {
+					return
+				}
+				continue
+			}
+			if herr := handler(v); herr != nil {
+				err = c.rowError(b.lines[i], b.records[i], model, herr)
+				return
+			}
+			c.delivered()
+		}
+		err = b.err
+	}
+
+	receive := func(b *batch[T]) {
+		running--
+		if c.unordered {
+			deliver(b)
+			return
+		}
+
+		pending[b.seq] = b
+		for b, ok := pending[next]; ok && err == nil; b, ok = pending[next] {
+			delete(pending, next)
+			next++
+			deliver(b)
+		}
+	}
+
+	// the batches waiting for an earlier one count too, so a slow worker does not let them pile up
+	for err == nil && (!eof || running > 0) {
+		if !eof && running+len(pending) < inflight {
+			b := &batch[T]{seq: seq, line: line}
+			for n := 0; n < batchSize; n++ {
+				data, rerr := br.ReadSlice('\n')
+				for rerr == bufio.ErrBufferFull {
+					b.data = append(b.data, data...)
+					data, rerr = br.ReadSlice('\n')
+				}
+				b.data = append(b.data, data...)
+				if rerr != nil {
+					if rerr != io.EOF {
+						b.err = rerr
+					}
+					eof = true
+					break
+				}
+				line++
+			}
+
+			if len(b.data) > 0 || b.err != nil {
+				seq++
+				running++
+				jobs <- b
+			}
+		} else {
+			receive(<-results)
+		}
+
+		for drained := false; !drained && err == nil; {
+			select {
+			case b := <-results:
+				receive(b)
+			default:
+				drained = true
+			}
+		}
+	}
+
+	stopped.Store(true)
+	close(jobs)
+	wg.Wait()
+	return err
+}
+
+// parse splits the lines of the batch into records. A malformed record is kept
+// with its error, so it is reported in the order of the file.
+func (b *batch[T]) parse(c *config, model reflect.Type, fields int) {
+	r := newReader(bytes.NewReader(b.data))
+	r.ReuseRecord = false
+	r.FieldsPerRecord = fields
+	offset := b.line - 1
+
+	b.records = make([][]string, 0, batchSize)
+	b.lines = make([]int, 0, batchSize)
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		var perr *csv.ParseError
+		if errors.As(err, &perr) {
+			perr.StartLine += offset
+			perr.Line += offset
+			if b.invalid == nil {
+				b.invalid = make([]*RowError, batchSize)
+			}
+			b.invalid[len(b.records)] = c.rowError(0, record, model, err)
+		} else if err != nil {
+			b.err = err
+			break
+		}
+		b.records = append(b.records, record)
+		b.lines = append(b.lines, r.Line()+offset)
+	}
+	b.data = nil
+}
+
+// decode decodes the records of the batch with a decoder reading them.
+func (b *batch[T]) decode(dec *csvutil.Decoder, c *config, model reflect.Type, col *column, stopped *atomic.Bool) {
+	b.values = make([]*T, len(b.records))
+	for i := range b.records {
+		if stopped.Load() {
+			b.values = b.values[:i]
+			return
+		}
+
+		v := new(T)
+		if err := dec.Decode(v); err != nil {
+			if b.invalid == nil {
+				b.invalid = make([]*RowError, len(b.records))
+			}
+			if b.invalid[i] == nil {
+				b.invalid[i] = c.decodeError(b.lines[i], b.records[i], model, col, err)
+			}
+			continue
+		}
+		b.values[i] = v
+	}
+}
+
+// recordReader passes the records of a batch to a csvutil.Decoder.
+type recordReader struct {
+	records [][]string
+}
+
+func (r *recordReader) Read() ([]string, error) {
+	if len(r.records) == 0 {
+		return nil, io.EOF
+	}
+
+	record := r.records[0]
+	r.records = r.records[1:]
+	return record, nil
+}
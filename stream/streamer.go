@@ -1,16 +1,50 @@
 package stream
 
 import (
+	"archive/zip"
+	"context"
 	"fmt"
 	"github.com/jszwec/csvutil"
 	"github.com/krolaw/zipstream"
 	"github.com/mkrou/geonames/csv"
 	"io"
+	"reflect"
 )
 
 func StreamArchive(r io.Reader, filename string, handler func(f func(v interface{}) error) error, missedHeaders []string) error {
+	return StreamArchiveContext(context.Background(), r, filename, handler, missedHeaders)
+}
+
+// StreamArchiveContext is like StreamArchive but stops when ctx is done.
+func StreamArchiveContext(ctx context.Context, r io.Reader, filename string, handler func(f func(v interface{}) error) error, missedHeaders []string) error {
+	return streamArchive(r, filename, func(entry io.Reader) error {
+		return StreamFileContext(ctx, entry, handler, missedHeaders)
+	})
+}
+
+// Archive decodes every record of the file with the given name in the zip archive
+// into a new T and passes it to handler. See File.
+func Archive[T any](ctx context.Context, r io.Reader, filename string, missedHeaders []string, handler func(*T) error, options ...Option) error {
+	options = append(options[:len(options):len(options)], func(c *config) {
+		c.entry = filename
+	})
+	return streamArchive(r, filename, func(entry io.Reader) error {
+		return File(ctx, entry, missedHeaders, handler, options...)
+	})
+}
+
+func streamArchive(r io.Reader, filename string, stream func(entry io.Reader) error) error {
+	src := &sourceReader{r: r}
+	err := findEntry(src, filename, stream)
+	if src.err != nil {
+		return src.err
+	}
+	return err
+}
+
+func findEntry(r io.Reader, filename string, stream func(entry io.Reader) error) error {
 	archive := zipstream.NewReader(r)
-	file, err := archive.Next()
+	file, err := nextEntry(archive)
 	if err != nil && err != io.EOF {
 		return err
 	}
@@ -21,27 +55,71 @@ func StreamArchive(r io.Reader, filename string, handler func(f func(v interface
 		}
 
 		if file.Name == filename {
-			return StreamFile(archive, handler, missedHeaders)
+			return stream(entryReader{archive})
 		}
 
-		file, err = archive.Next()
+		file, err = nextEntry(archive)
 	}
 
 	return fmt.Errorf("Archive doesnt contain the file %s", filename)
 }
 
+// sourceReader keeps the first read error of the underlying reader,
+// so it is reported instead of the errors of the truncated archive.
+type sourceReader struct {
+	r   io.Reader
+	err error
+}
+
+func (s *sourceReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	if err != nil && err != io.EOF && s.err == nil {
+		s.err = err
+	}
+	return n, err
+}
+
+// entryReader reads an archive entry and turns the panics of zipstream
+// on a truncated archive into io.ErrUnexpectedEOF.
+type entryReader struct {
+	r io.Reader
+}
+
+func (e entryReader) Read(p []byte) (n int, err error) {
+	defer func() {
+		if recover() != nil {
+			n, err = 0, io.ErrUnexpectedEOF
+		}
+	}()
+	return e.r.Read(p)
+}
+
+func nextEntry(archive *zipstream.Reader) (file *zip.FileHeader, err error) {
+	defer func() {
+		if recover() != nil {
+			file, err = nil, io.ErrUnexpectedEOF
+		}
+	}()
+	return archive.Next()
+}
+
 func StreamFile(reader io.Reader, handler func(f func(v interface{}) error) error, missedHeaders []string) error {
-	r := csv.NewReader(reader)
-	r.Comma = '\t'
-	r.Comment = '#'
-	r.ReuseRecord = true
+	return StreamFileContext(context.Background(), reader, handler, missedHeaders)
+}
 
-	dec, err := csvutil.NewDecoder(r, missedHeaders...)
+// StreamFileContext is like StreamFile but checks ctx between records
+// and returns ctx.Err() once it is done.
+func StreamFileContext(ctx context.Context, reader io.Reader, handler func(f func(v interface{}) error) error, missedHeaders []string) error {
+	dec, err := newDecoder(reader, missedHeaders)
 	if err != nil {
 		return err
 	}
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		err := handler(dec.Decode)
 		if err == io.EOF {
 			break
@@ -55,3 +133,65 @@ func StreamFile(reader io.Reader, handler func(f func(v interface{}) error) erro
 
 	return nil
 }
+
+// File decodes every record of the tab separated file into a new T
+// and passes it to handler until the end of the file, the first error or ctx is done.
+// If missedHeaders is empty, the first line of the file is used as the header.
+// Records that can not be decoded are handled as set by SkipInvalid, OnInvalid or FailFast.
+// An error of the handler is returned as a *RowError wrapping it.
+func File[T any](ctx context.Context, reader io.Reader, missedHeaders []string, handler func(*T) error, options ...Option) error {
+	c := newConfig(options)
+	if c.workers > 1 {
+		return parallel(ctx, reader, missedHeaders, handler, c)
+	}
+
+	src := &recordSource{r: newReader(reader)}
+	headers, err := c.header(src, missedHeaders)
+	if err != nil {
+		return err
+	}
+	src.r.FieldsPerRecord = len(headers)
+	dec, err := csvutil.NewDecoder(src, headers...)
+	if err != nil {
+		return err
+	}
+	model := reflect.TypeOf((*T)(nil)).Elem()
+	col := &column{}
+	col.watch(dec)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		v := new(T)
+		if err := dec.Decode(v); err == io.EOF {
+			return nil
+		} else if err != nil {
+			if src.readErr != nil {
+				return err
+			}
+			if err := c.invalid(c.decodeError(src.line, dec.Record(), model, col, err)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := handler(v); err != nil {
+			return c.rowError(src.line, dec.Record(), model, err)
+		}
+		c.delivered()
+	}
+}
+
+func newDecoder(reader io.Reader, missedHeaders []string) (*csvutil.Decoder, error) {
+	return csvutil.NewDecoder(newReader(reader), missedHeaders...)
+}
+
+func newReader(reader io.Reader) *csv.Reader {
+	r := csv.NewReader(reader)
+	r.Comma = '\t'
+	r.Comment = '#'
+	r.ReuseRecord = true
+	return r
+}
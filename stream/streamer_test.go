@@ -0,0 +1,242 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jszwec/csvutil"
+	"github.com/mkrou/geonames/csv"
+	"github.com/mkrou/geonames/internal/fixture"
+	"github.com/mkrou/geonames/models"
+	. "github.com/smartystreets/goconvey/convey"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func geonameHeaders() []string {
+	headers, err := csvutil.Header(models.Geoname{}, "csv")
+	if err != nil {
+		panic(err)
+	}
+	return headers
+}
+
+func ids(data string, options ...Option) ([]int, error) {
+	var x []int
+	err := File(context.Background(), strings.NewReader(data), geonameHeaders(), func(g *models.Geoname) error {
+		x = append(x, g.Id)
+		return nil
+	}, options...)
+	return x, err
+}
+
+func TestWorkers(t *testing.T) {
+	Convey("Given a file with more records than a batch", t, func() {
+		data := fixture.Geonames(5*batchSize + 7)
+		expected, err := ids(data)
+		So(err, ShouldBeNil)
+		So(len(expected), ShouldEqual, 5*batchSize+7)
+
+		Convey("When it is decoded by ordered workers", func() {
+			x, err := ids(data, Workers(4))
+
+			Convey("The records should be passed in the order of the file", func() {
+				So(err, ShouldBeNil)
+				So(x, ShouldResemble, expected)
+			})
+		})
+
+		Convey("When it is decoded by unordered workers", func() {
+			x, err := ids(data, Workers(4), Unordered())
+			sort.Ints(x)
+
+			Convey("Every record should be passed once", func() {
+				So(err, ShouldBeNil)
+				So(x, ShouldResemble, expected)
+			})
+		})
+
+		Convey("When a record in the middle can not be decoded", func() {
+			lines := strings.SplitAfter(data, "\n")
+			lines[2*batchSize+3] = strings.Replace(lines[2*batchSize+3], "\tP\tPPL\t", "\tP\tPPL\textra\t", 1)
+			x, err := ids(strings.Join(lines, ""), Workers(4))
+
+			Convey("The records before it should be passed before the error", func() {
+				So(err, ShouldNotBeNil)
+				So(x, ShouldResemble, expected[:2*batchSize+3])
+			})
+		})
+
+		Convey("When the handler fails", func() {
+			failure := errors.New("failure")
+			count := 0
+			err := File(context.Background(), strings.NewReader(data), geonameHeaders(), func(g *models.Geoname) error {
+				count++
+				if count == batchSize+1 {
+					return failure
+				}
+				return nil
+			}, Workers(4))
+
+			Convey("The error should be returned and no more records passed", func() {
+				So(errors.Is(err, failure), ShouldBeTrue)
+				So(count, ShouldEqual, batchSize+1)
+			})
+		})
+	})
+}
+
+var (
+	benchmarkOnce sync.Once
+	benchmarkData string
+)
+
+// benchmarkFile measures decoding a synthetic file with a million rows.
+func benchmarkFile(b *testing.B, options ...Option) {
+	benchmarkOnce.Do(func() {
+		benchmarkData = fixture.Geonames(1000000)
+	})
+	headers := geonameHeaders()
+	b.SetBytes(int64(len(benchmarkData)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		err := File(context.Background(), strings.NewReader(benchmarkData), headers, func(g *models.Geoname) error {
+			return nil
+		}, options...)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFile(b *testing.B) {
+	benchmarkFile(b)
+}
+
+func BenchmarkFileWorkers2(b *testing.B) {
+	benchmarkFile(b, Workers(2))
+}
+
+func BenchmarkFileWorkers4(b *testing.B) {
+	benchmarkFile(b, Workers(4))
+}
+
+func BenchmarkFileWorkers8(b *testing.B) {
+	benchmarkFile(b, Workers(8))
+}
+
+func BenchmarkFileWorkers8Unordered(b *testing.B) {
+	benchmarkFile(b, Workers(8), Unordered())
+}
+
+func TestSkipColumns(t *testing.T) {
+	Convey("Given a file with more records than a batch", t, func() {
+		data := fixture.Geonames(batchSize + 3)
+
+		for _, workers := range []int{1, 4} {
+			Convey(fmt.Sprintf("When the alternate names are skipped by %d workers", workers), func() {
+				var x []*models.Geoname
+				err := File(context.Background(), strings.NewReader(data), geonameHeaders(), func(g *models.Geoname) error {
+					x = append(x, g)
+					return nil
+				}, Workers(workers), SkipColumns("alternatenames"))
+
+				Convey("Only the skipped column should be left empty", func() {
+					So(err, ShouldBeNil)
+					So(len(x), ShouldEqual, batchSize+3)
+					So(x[4].AlternateNames, ShouldBeNil)
+					So(x[4].Name, ShouldEqual, "Place 5")
+					So(x[4].Timezone, ShouldEqual, "Europe/Andorra")
+				})
+			})
+		}
+	})
+
+	Convey("Given a file with a header line", t, func() {
+		data := "ISO 639-3\tISO 639-2\tISO 639-1\tLanguage Name\nrus\trus\tru\tRussian\n"
+
+		Convey("When a column is skipped", func() {
+			var x *models.Language
+			err := File(context.Background(), strings.NewReader(data), nil, func(l *models.Language) error {
+				x = l
+				return nil
+			}, SkipColumns("ISO 639-2"))
+
+			So(err, ShouldBeNil)
+			So(x.Iso639_2, ShouldBeEmpty)
+			So(x.Name, ShouldEqual, "Russian")
+		})
+	})
+}
+
+func TestErrorPolicy(t *testing.T) {
+	Convey("Given a file with a malformed and an undecodable record", t, func() {
+		lines := strings.SplitAfter(fixture.Geonames(2*batchSize), "\n")
+		lines[10] = "11\tPlace 11\n"
+		lines[batchSize+5] = strings.Replace(lines[batchSize+5], "\tP\tPPL\t", "\tP\tPPL\textra\t", 1)
+		lines[batchSize+20] = strings.Replace(lines[batchSize+20], fmt.Sprintf("\t%d\t\t", (batchSize+21)*7%10000), "\tmany\t\t", 1)
+		data := strings.Join(lines, "")
+
+		for _, workers := range []int{1, 4} {
+			Convey(fmt.Sprintf("When it is parsed by %d workers and invalid records are skipped", workers), func() {
+				report := &Report{}
+				x, err := ids(data, Workers(workers), SkipInvalid(report))
+
+				Convey("The valid records should be passed", func() {
+					So(err, ShouldBeNil)
+					So(len(x), ShouldEqual, 2*batchSize-3)
+					So(report.Records, ShouldEqual, 2*batchSize-3)
+				})
+
+				Convey("The invalid records should be reported", func() {
+					So(report.Skipped, ShouldEqual, 3)
+					So(len(report.Errors), ShouldEqual, 3)
+					So(report.Errors[0].Line, ShouldEqual, 11)
+					So(report.Errors[0].Record, ShouldEqual, "11\tPlace 11")
+					So(report.Errors[0].Model, ShouldEqual, "models.Geoname")
+					for _, e := range report.Errors[:2] {
+						var perr *csv.ParseError
+						So(errors.As(e.Err, &perr), ShouldBeTrue)
+						So(perr.Err, ShouldEqual, csv.ErrFieldCount)
+						So(perr.StartLine, ShouldEqual, e.Line)
+					}
+					So(report.Errors[1].Column, ShouldBeEmpty)
+					So(report.Errors[1].Line, ShouldEqual, batchSize+6)
+					So(report.Errors[2].Line, ShouldEqual, batchSize+21)
+					So(report.Errors[2].Record, ShouldEqual, strings.TrimSuffix(lines[batchSize+20], "\n"))
+				})
+			})
+
+			Convey(fmt.Sprintf("When it is parsed by %d workers with a callback", workers), func() {
+				var seen []int
+				failure := errors.New("failure")
+				x, err := ids(data, Workers(workers), OnInvalid(func(e *RowError) error {
+					seen = append(seen, e.Line)
+					if len(seen) == 2 {
+						return failure
+					}
+					return nil
+				}))
+
+				Convey("The callback should decide whether to continue", func() {
+					So(err, ShouldEqual, failure)
+					So(seen, ShouldResemble, []int{11, batchSize + 6})
+					So(len(x), ShouldEqual, batchSize+4)
+				})
+			})
+
+			Convey(fmt.Sprintf("When it is parsed by %d workers with the default policy", workers), func() {
+				x, err := ids(data, Workers(workers))
+
+				Convey("The parsing should stop at the first invalid record", func() {
+					So(err, ShouldNotBeNil)
+					So(len(x), ShouldEqual, 10)
+				})
+			})
+		}
+	})
+}
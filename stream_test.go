@@ -0,0 +1,154 @@
+package geonames
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mkrou/geonames/models"
+	"github.com/mkrou/geonames/stream"
+	. "github.com/smartystreets/goconvey/convey"
+	"strings"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+	Convey("Given a parser over a local mirror", t, func() {
+		dir := mirror(t, map[string][]byte{
+			"cities500.zip":    zipped("cities500.txt", geonameRows),
+			LangCodes.String(): []byte("ISO 639-3\tISO 639-2\tISO 639-1\tLanguage Name\nrus\trus\tru\tRussian\n"),
+		})
+		p := NewDirParser(dir)
+
+		Convey("When a model is streamed", func() {
+			var x []*models.Geoname
+			err := Stream(p, models.DumpFile(Cities500), func(g *models.Geoname) error {
+				x = append(x, g)
+				return nil
+			})
+
+			Convey("Every record should be decoded into its own value", func() {
+				So(err, ShouldBeNil)
+				So(len(x), ShouldEqual, 3)
+				So(x[0].Name, ShouldEqual, "El Tarter")
+				So(x[2].Name, ShouldEqual, "Pas de la Casa")
+			})
+
+			Convey("The comma separated columns should be split", func() {
+				So(x[0].AlternateNames, ShouldResemble, models.StringList{"Ehl'-Tarter", "El Tarter"})
+				So(x[0].AlternateCountryCodes, ShouldBeNil)
+			})
+		})
+
+		Convey("When a file with a header line is streamed", func() {
+			var x *models.Language
+			err := Stream(p, LangCodes, func(l *models.Language) error {
+				x = l
+				return nil
+			})
+
+			So(err, ShouldBeNil)
+			So(x.Name, ShouldEqual, "Russian")
+			So(x.Iso639_1, ShouldEqual, "ru")
+		})
+
+		Convey("When the options are passed in a slice with spare capacity", func() {
+			options := make([]stream.Option, 1, 4)
+			options[0] = stream.Workers(1)
+			err := Stream(p, models.DumpFile(Cities500), func(g *models.Geoname) error {
+				return nil
+			}, options...)
+
+			Convey("The slice of the caller should not be written to", func() {
+				So(err, ShouldBeNil)
+				So(options[:2][1], ShouldBeNil)
+			})
+		})
+	})
+}
+
+func TestErrStop(t *testing.T) {
+	Convey("Given a parser over a local mirror", t, func() {
+		dir := mirror(t, map[string][]byte{
+			"cities500.zip": zipped("cities500.txt", geonameRows),
+		})
+		files, p := track(NewDirParser(dir))
+
+		Convey("When a handler returns ErrStop", func() {
+			count := 0
+			err := p.GetGeonames(Cities500, func(x *models.Geoname) error {
+				count++
+				return ErrStop
+			})
+
+			Convey("The parsing should end without an error", func() {
+				So(err, ShouldBeNil)
+				So(count, ShouldEqual, 1)
+			})
+
+			Convey("The file should be closed", func() {
+				So(files, shouldHaveClosedAll)
+			})
+		})
+
+		Convey("When a handler returns a wrapped ErrStop", func() {
+			err := p.GetGeonames(Cities500, func(x *models.Geoname) error {
+				return fmt.Errorf("found %d: %w", x.Id, ErrStop)
+			})
+
+			So(err, ShouldBeNil)
+		})
+
+		Convey("When a handler returns another error", func() {
+			failure := errors.New("failure")
+			err := p.GetGeonames(Cities500, func(x *models.Geoname) error {
+				return failure
+			})
+
+			So(errors.Is(err, failure), ShouldBeTrue)
+			So(files, shouldHaveClosedAll)
+		})
+	})
+}
+
+func TestRowErrors(t *testing.T) {
+	Convey("Given an archive with a population that is not a number", t, func() {
+		dir := mirror(t, map[string][]byte{
+			"cities500.zip": zipped("cities500.txt", "# comment\n"+strings.Replace(geonameRows, "\t8022\t", "\tmany\t", 1)),
+		})
+		p := NewDirParser(dir)
+
+		for _, workers := range []int{1, 2} {
+			Convey(fmt.Sprintf("When it is parsed by %d workers", workers), func() {
+				err := Stream(p, models.DumpFile(Cities500), func(x *models.Geoname) error {
+					return nil
+				}, stream.Workers(workers))
+
+				Convey("The error should point at the value", func() {
+					var rowErr *stream.RowError
+					So(errors.As(err, &rowErr), ShouldBeTrue)
+					So(rowErr.File, ShouldEqual, "cities500.zip")
+					So(rowErr.Entry, ShouldEqual, "cities500.txt")
+					So(rowErr.Line, ShouldEqual, 3)
+					So(rowErr.Column, ShouldEqual, "population")
+					So(rowErr.Value, ShouldEqual, "many")
+					So(rowErr.Record, ShouldStartWith, "3039163\tSant Julià de Lòria\t")
+					So(err.Error(), ShouldStartWith, `cities500.zip: cities500.txt: line 3, column "population": `)
+				})
+			})
+		}
+
+		Convey("When a handler fails", func() {
+			failure := errors.New("failure")
+			err := p.GetGeonames(Cities500, func(x *models.Geoname) error {
+				return failure
+			})
+
+			Convey("The error should be wrapped with the line of the record", func() {
+				var rowErr *stream.RowError
+				So(errors.As(err, &rowErr), ShouldBeTrue)
+				So(errors.Is(err, failure), ShouldBeTrue)
+				So(rowErr.Line, ShouldEqual, 2)
+				So(rowErr.Column, ShouldEqual, "")
+			})
+		})
+	})
+}